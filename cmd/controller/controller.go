@@ -15,8 +15,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
 	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/artifact"
 	"github.com/qiankunli/workflow/pkg/controller/manager"
 	"github.com/qiankunli/workflow/pkg/controller/operators"
+	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
+	"github.com/qiankunli/workflow/pkg/controller/step/common"
+	"github.com/qiankunli/workflow/pkg/controller/webhooks"
 	"github.com/qiankunli/workflow/pkg/options"
 	"github.com/qiankunli/workflow/pkg/version"
 )
@@ -54,6 +58,7 @@ func newControllerCmd(ctx context.Context, opt *Option, config *options.Config)
 			}
 
 			ctrl.SetLogger(klogr.New())
+			webhookConfig := controllerCtx.Config.ControllerConfig.Webhook
 			mgr, err := ctrl.NewManager(restConf, ctrl.Options{
 				Scheme: Scheme,
 				// Namespace为空即为监听所有namespace
@@ -64,6 +69,8 @@ func newControllerCmd(ctx context.Context, opt *Option, config *options.Config)
 				HealthProbeBindAddress:  opt.HealthProbeBindAddress,
 				LeaderElectionID:        opt.LeaderElectionID,
 				LeaderElectionNamespace: opt.LeaderElectionNamespace,
+				Port:                    webhookConfig.Port,
+				CertDir:                 webhookConfig.CertDir,
 			})
 
 			if err != nil {
@@ -76,6 +83,13 @@ func newControllerCmd(ctx context.Context, opt *Option, config *options.Config)
 				return err
 			}
 
+			if webhookConfig.Enabled {
+				if err := setupWebhooks(mgr, controllerCtx); err != nil {
+					klog.Errorf("unable to setup webhooks: %v", err)
+					return err
+				}
+			}
+
 			// Readiness and health check
 			if err := mgr.AddReadyzCheck("ping", healthz.Ping); err != nil {
 				klog.Fatalf("unable to create ready check, err: %v", err)
@@ -104,6 +118,14 @@ func NewCommand(ctx context.Context, config *options.Config) *cobra.Command {
 }
 
 func setupReconcilers(mgr ctrl.Manager, controllerContext *manager.ControllerContext) error {
+	// workflow step 通过manager 的client 创建、查询子workflow
+	common.SetClient(mgr.GetClient())
+	// step 通过PutArtifact/GetArtifact 读写artifact
+	storage, err := artifact.NewStorage(v1alpha1.ArtifactStorage(controllerContext.Config.ControllerConfig.Artifact.Storage), mgr.GetClient())
+	if err != nil {
+		return err
+	}
+	stepinterface.SetArtifactStorage(storage)
 	// register step controller
 	for _, stepConfig := range controllerContext.Config.ControllerConfig.Steps {
 		if err := operators.RegisterStepReconciler(mgr, controllerContext, stepConfig); err != nil {
@@ -114,6 +136,10 @@ func setupReconcilers(mgr ctrl.Manager, controllerContext *manager.ControllerCon
 	if err := operators.RegisterWorkflowReconciler(mgr, controllerContext); err != nil {
 		return err
 	}
+	// register cron workflow controller
+	if err := operators.RegisterCronWorkflowReconciler(mgr, controllerContext); err != nil {
+		return err
+	}
 	// register queue controller
 	if err := operators.RegisterQueueReconciler(mgr, controllerContext); err != nil {
 		return err
@@ -121,3 +147,22 @@ func setupReconcilers(mgr ctrl.Manager, controllerContext *manager.ControllerCon
 
 	return nil
 }
+
+func setupWebhooks(mgr ctrl.Manager, controllerContext *manager.ControllerContext) error {
+	if err := webhooks.RegisterWorkflowWebhook(mgr, controllerContext); err != nil {
+		return err
+	}
+	if err := webhooks.RegisterStepWebhook(mgr, controllerContext); err != nil {
+		return err
+	}
+	if err := webhooks.RegisterWorkflowTemplateWebhook(mgr, controllerContext); err != nil {
+		return err
+	}
+	if err := webhooks.RegisterCronWorkflowWebhook(mgr, controllerContext); err != nil {
+		return err
+	}
+	if err := webhooks.RegisterApprovalWebhook(mgr, controllerContext); err != nil {
+		return err
+	}
+	return nil
+}
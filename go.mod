@@ -3,8 +3,10 @@ module github.com/qiankunli/workflow
 go 1.20
 
 require (
+	github.com/Knetic/govaluate v3.0.0+incompatible
 	github.com/go-logr/logr v1.2.4
 	github.com/pkg/errors v0.9.1
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/spf13/cobra v1.7.0
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.16.0
@@ -0,0 +1,105 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CronWorkflowSpec defines the desired state of CronWorkflow
+type CronWorkflowSpec struct {
+	// 标准的5段cron 表达式，比如 "0 2 * * *"
+	Schedule string `json:"schedule"`
+	// IANA 时区，比如Asia/Shanghai，为空时使用controller 所在的时区
+	Timezone string `json:"timezone,omitempty"`
+	// 上一次创建的workflow 还在运行时如何处理
+	// +kubebuilder:default:=Allow
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+	// 错过调度时间超过该值则不再创建workflow，为空表示不限制
+	// +kubebuilder:validation:Minimum=0
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// 保留的成功workflow 数量，超过时删除最早的workflow，删除时不回滚
+	// +kubebuilder:default:=3
+	// +kubebuilder:validation:Minimum=0
+	SuccessfulHistoryLimit *int32 `json:"successfulHistoryLimit,omitempty"`
+	// 保留的失败(RollBacked、Failed)workflow 数量
+	// +kubebuilder:default:=1
+	// +kubebuilder:validation:Minimum=0
+	FailedHistoryLimit *int32 `json:"failedHistoryLimit,omitempty"`
+	// 为true 时不再创建新的workflow，不影响已经创建的workflow
+	Suspend bool `json:"suspend,omitempty"`
+	// 创建的workflow 的spec
+	WorkflowSpec WorkflowSpec `json:"workflowSpec"`
+}
+
+// ConcurrencyPolicy
+// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+type ConcurrencyPolicy string
+
+const (
+	// AllowConcurrent 允许多个workflow 同时运行
+	AllowConcurrent ConcurrencyPolicy = "Allow"
+	// ForbidConcurrent 上一个workflow 还在运行时跳过本次调度
+	ForbidConcurrent ConcurrencyPolicy = "Forbid"
+	// ReplaceConcurrent 删除(回滚)还在运行的workflow，再创建新的workflow
+	ReplaceConcurrent ConcurrencyPolicy = "Replace"
+)
+
+// CronWorkflowStatus defines the observed state of CronWorkflow
+type CronWorkflowStatus struct {
+	// 还没有结束的workflow
+	Active []corev1.ObjectReference `json:"active,omitempty"`
+	// 最近一次成功创建workflow 的调度时间
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// 最近一次成功的workflow 的调度时间
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+}
+
+// CronWorkflow is the Schema for the cronworkflows API
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=cronworkflows,shortName=cwf,scope=Namespaced
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule",description="cron schedule. "
+// +kubebuilder:printcolumn:name="Timezone",type="string",JSONPath=".spec.timezone",description="timezone of the schedule. "
+// +kubebuilder:printcolumn:name="Suspend",type="boolean",JSONPath=".spec.suspend",description="whether new workflows are suspended. "
+// +kubebuilder:printcolumn:name="LastSchedule",type="date",JSONPath=".status.lastScheduleTime",description="last schedule time. "
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="CreationTimestamp is a timestamp representing the server time when this object was created. "
+type CronWorkflow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CronWorkflowSpec   `json:"spec,omitempty"`
+	Status CronWorkflowStatus `json:"status,omitempty"`
+}
+
+// CronWorkflowList contains a list of CronWorkflow
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CronWorkflowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CronWorkflow `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CronWorkflow{}, &CronWorkflowList{})
+}
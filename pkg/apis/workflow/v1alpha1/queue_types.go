@@ -0,0 +1,80 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// QueueSpec defines the desired state of Queue
+type QueueSpec struct {
+	// 该queue 可以同时运行的workflow 数量，小于等于0 表示不限制，同时受全局maxRunningCount 限制
+	MaxRunning int32 `json:"maxRunning,omitempty"`
+	// FAIR 策略下按 running/weight 从小到大选择queue，weight 越大分到的运行名额越多
+	// +kubebuilder:default:=1
+	// +kubebuilder:validation:Minimum=1
+	Weight int32 `json:"weight,omitempty"`
+	// FAIR 策略下优先调度priority 高的queue
+	Priority int32 `json:"priority,omitempty"`
+	// 暂停后不再启动该queue 新的workflow，已经运行的workflow 不受影响
+	Paused bool `json:"paused,omitempty"`
+}
+
+// QueueStatus defines the observed state of Queue
+type QueueStatus struct {
+	// 运行中、回滚中的workflow 数量
+	Running int32 `json:"running,omitempty"`
+	// 等待运行的workflow 数量
+	Pending int32 `json:"pending,omitempty"`
+}
+
+// Queue is the Schema for the queues API, workflow 通过spec.queue 关联同名的Queue
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=queues,shortName=wfq,scope=Cluster
+// +kubebuilder:printcolumn:name="MaxRunning",type="integer",JSONPath=".spec.maxRunning",description="max running workflow count"
+// +kubebuilder:printcolumn:name="Weight",type="integer",JSONPath=".spec.weight",description="queue weight"
+// +kubebuilder:printcolumn:name="Priority",type="integer",JSONPath=".spec.priority",description="queue priority"
+// +kubebuilder:printcolumn:name="Paused",type="boolean",JSONPath=".spec.paused",description="whether queue is paused"
+// +kubebuilder:printcolumn:name="Running",type="integer",JSONPath=".status.running",description="running workflow count"
+// +kubebuilder:printcolumn:name="Pending",type="integer",JSONPath=".status.pending",description="pending workflow count"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="CreationTimestamp is a timestamp representing the server time when this object was created. "
+type Queue struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   QueueSpec   `json:"spec,omitempty"`
+	Status QueueStatus `json:"status,omitempty"`
+}
+
+// QueueList contains a list of Queue
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type QueueList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Queue `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Queue{}, &QueueList{})
+}
@@ -17,7 +17,9 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -32,8 +34,71 @@ type RetryPolicy struct { // 描述该step 运行或回滚时的重试情况
 	RollbackRetryLimit int32 `json:"rollbackRetryLimit,omitempty"`
 	// +kubebuilder:default:=60
 	RollbackRetryPeriodSeconds int32 `json:"rollbackRetryPeriodSeconds,omitempty"`
+	// 重试间隔的退避策略，同时作用于运行和回滚的重试
+	// +kubebuilder:default:=Fixed
+	Backoff BackoffStrategy `json:"backoff,omitempty"`
+	// Exponential 时第n 次重试间隔为 period*factor^(n-1)，Linear 时为 period*(1+factor*(n-1))，小于等于0 时按2 计算
+	// +kubebuilder:default:=2
+	BackoffFactor int32 `json:"backoffFactor,omitempty"`
+	// 重试间隔的上限，小于等于0 表示不限制
+	MaxRetryPeriodSeconds int32 `json:"maxRetryPeriodSeconds,omitempty"`
+	// 在重试间隔上随机增加 [0, jitterPercent%) 的时间，防止大量step 同时重试
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	JitterPercent int32 `json:"jitterPercent,omitempty"`
+	// 按StepError 的code 定制重试行为，按顺序匹配第一条
+	Rules []RetryRule `json:"rules,omitempty"`
 }
 
+type RetryRule struct { // 描述step 返回某个code 的错误时如何处理
+	// 匹配StepError 的code
+	Code string `json:"code"`
+	// 规则作用于Run 还是Rollback，为空表示都作用
+	Operation RetryOperation `json:"operation,omitempty"`
+	Action    RetryAction    `json:"action"`
+	// Action 为Retry 时该code 单独计数的重试次数上限，不占用RunRetryLimit/RollbackRetryLimit，小于等于0 表示按默认方式计数
+	RetryLimit int32 `json:"retryLimit,omitempty"`
+	// Action 为Retry 时该code 的重试间隔，小于等于0 表示使用默认的重试间隔
+	RetryPeriodSeconds int32 `json:"retryPeriodSeconds,omitempty"`
+}
+
+// RetryOperation
+// +kubebuilder:validation:Enum=Run;Rollback
+type RetryOperation string
+
+const (
+	RunOperation      RetryOperation = "Run"
+	RollbackOperation RetryOperation = "Rollback"
+)
+
+// RetryAction
+// +kubebuilder:validation:Enum=Retry;Ignore;Fail;Succeed
+type RetryAction string
+
+const (
+	// ActionRetry 可重试，计入重试次数
+	ActionRetry RetryAction = "Retry"
+	// ActionIgnore 可重试，不计入重试次数，比如限流
+	ActionIgnore RetryAction = "Ignore"
+	// ActionFail 不可重试
+	ActionFail RetryAction = "Fail"
+	// ActionSucceed 视为执行成功，比如回滚时资源已不存在
+	ActionSucceed RetryAction = "Succeed"
+)
+
+// BackoffStrategy
+// +kubebuilder:validation:Enum=Fixed;Exponential;Linear
+type BackoffStrategy string
+
+const (
+	// FixedBackoff 固定重试间隔
+	FixedBackoff BackoffStrategy = "Fixed"
+	// ExponentialBackoff 重试间隔按factor 指数增长
+	ExponentialBackoff BackoffStrategy = "Exponential"
+	// LinearBackoff 重试间隔按factor 线性增长
+	LinearBackoff BackoffStrategy = "Linear"
+)
+
 // StepSpec defines the desired state of Step
 type StepSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
@@ -42,16 +107,31 @@ type StepSpec struct {
 	Type string `json:"type,omitempty"`
 	// Map类型的数据
 	Parameters map[string]string `json:"parameters,omitempty"`
+	// 从Secret/ConfigMap 读取的parameters，只在step 运行时解析到内存中，secret 的值会从错误信息、事件和回调中抹去
+	ParametersFrom []ParameterFrom `json:"parametersFrom,omitempty"`
 	// +kubebuilder:default:=PreserveOnFailure
 	RollbackPolicy RollbackPolicy `json:"rollbackPolicy,omitempty"`
 	RetryPolicy    RetryPolicy    `json:"retryPolicy,omitempty"`
 	// 小于等于0 表示不进行sync
 	// +kubebuilder:default:=0
 	SyncPeriodSeconds int32 `json:"syncPeriodSeconds,omitempty"`
+	// 异步step 提交任务后，轮询任务状态的间隔
+	// +kubebuilder:default:=10
+	PollPeriodSeconds int32 `json:"pollPeriodSeconds,omitempty"`
+	// step 处于Running 的最长时间(包含重试)，超时则开始回滚，小于等于0 表示不限制
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// ParameterFrom 从与step 同namespace 的Secret 或ConfigMap 中读取parameter，secretKeyRef、configMapKeyRef 只能设置一个
+type ParameterFrom struct {
+	// parameter 的key，不能与parameters 重复
+	Name            string                       `json:"name"`
+	SecretKeyRef    *corev1.SecretKeySelector    `json:"secretKeyRef,omitempty"`
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
 }
 
 // StepPhase
-// +kubebuilder:validation:Enum=Pending;Running;Success;RollingBack;RollBacked;Failed
+// +kubebuilder:validation:Enum=Pending;Running;Success;RollingBack;RollBacked;Failed;Skipped
 type StepPhase string
 
 const (
@@ -61,6 +141,7 @@ const (
 	StepRollingBack StepPhase = "RollingBack"
 	StepRollBacked  StepPhase = "RollBacked"
 	StepFailed      StepPhase = "Failed"
+	StepSkipped     StepPhase = "Skipped" // when 不满足，step 不会运行也不需要回滚
 )
 
 type StepResource struct {
@@ -71,6 +152,26 @@ type StepResource struct {
 	Attributes map[string]string `json:"attributes,omitempty"`
 }
 
+// ArtifactStorage 存放artifact 的对象类型
+type ArtifactStorage string
+
+const (
+	ConfigMapArtifactStorage ArtifactStorage = "ConfigMap"
+	SecretArtifactStorage    ArtifactStorage = "Secret"
+)
+
+// ArtifactRef step 写入的artifact 的引用，内容存放在与step 同namespace、owner 为workflow 的ConfigMap/Secret 中
+type ArtifactRef struct {
+	Name string `json:"name"`
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	Storage ArtifactStorage `json:"storage"`
+	// 存放artifact 的ConfigMap/Secret 名称，为 <step>.<name>
+	ObjectName string `json:"objectName"`
+	Key        string `json:"key"`
+	// artifact 的字节数
+	Size int64 `json:"size,omitempty"`
+}
+
 // StepStatus defines the observed state of Step
 type StepStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -90,6 +191,43 @@ type StepStatus struct {
 	RunError              string            `json:"runError,omitempty"`
 	RollbackError         string            `json:"rollbackError,omitempty"`
 	SyncError             string            `json:"syncError,omitempty"`
+	// 异步step 提交任务的时间，不为空表示任务已提交，等待轮询结果
+	SubmittedAt  metav1.Time `json:"submittedAt,omitempty"`
+	LatestPollAt metav1.Time `json:"latestPollAt,omitempty"`
+	// 第一次进入Running 的时间，用于计算是否超时
+	StartedAt metav1.Time `json:"startedAt,omitempty"`
+	// 进入当前phase 的原因，比如Timeout
+	Reason string `json:"reason,omitempty"`
+	// 根据退避策略计算出的下次运行、回滚重试的时间
+	NextRunRetryAt      metav1.Time `json:"nextRunRetryAt,omitempty"`
+	NextRollbackRetryAt metav1.Time `json:"nextRollbackRetryAt,omitempty"`
+	// 按RetryRule 单独计数的重试次数，key 为 <operation>/<code>
+	RetryCodeCounts map[string]int32 `json:"retryCodeCounts,omitempty"`
+	// 进入Running 时已满足的dependOns 对应的step，回滚时这些step 要等本step 回滚完成
+	DependOns []string `json:"dependOns,omitempty"`
+	// 进入Running 时解析出的parameters，只包含带有 {{...}} 模板的parameter，step 运行时用来替换spec.parameters
+	ResolvedParameters map[string]string `json:"resolvedParameters,omitempty"`
+	// step 输出的结构化数据，为JSON object，可以通过 {{steps.<step>.outputs.<path>}} 引用
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Outputs *runtime.RawExtension `json:"outputs,omitempty"`
+	// step 写入的artifact，status 中只记录引用
+	Artifacts []ArtifactRef `json:"artifacts,omitempty"`
+	// 之前失败的尝试，通过workflow.example.com/retry 重试Failed 的step 时追加
+	Attempts []StepAttempt `json:"attempts,omitempty"`
+}
+
+// StepAttempt step 重试前的状态
+type StepAttempt struct {
+	RunRetryCount      int32  `json:"runRetryCount,omitempty"`
+	RollbackRetryCount int32  `json:"rollbackRetryCount,omitempty"`
+	RunError           string `json:"runError,omitempty"`
+	RollbackError      string `json:"rollbackError,omitempty"`
+	Reason             string `json:"reason,omitempty"`
+	// 该次尝试进入Running 的时间
+	StartedAt metav1.Time `json:"startedAt,omitempty"`
+	// 重试的时间，即该次尝试结束的时间
+	RetriedAt metav1.Time `json:"retriedAt,omitempty"`
 }
 
 // Step is the Schema for the steps API
@@ -130,4 +268,23 @@ const (
 	PhaseChangeReason   = "PhaseChange"
 	FailedOrErrorReason = "FailedOrError"
 	SpecWrongReason     = "SpecWrong"
+	SubmittedReason     = "Submitted"
+	// TimeoutReason step 运行超时
+	TimeoutReason = "Timeout"
+	// DeadlineExceededReason workflow 运行超过activeDeadlineSeconds
+	DeadlineExceededReason = "DeadlineExceeded"
+	// TemplateResolvedReason workflow 引用的模板已解析并快照到status
+	TemplateResolvedReason = "TemplateResolved"
+	// ScheduledReason CronWorkflow 按调度时间创建了workflow
+	ScheduledReason = "Scheduled"
+	// SkippedScheduleReason CronWorkflow 因为并发策略或错过太多调度跳过了本次调度
+	SkippedScheduleReason = "SkippedSchedule"
+	// SuspendedReason workflow 因为spec.suspend 暂停
+	SuspendedReason = "Suspended"
+	// ResumedReason workflow 从暂停中恢复
+	ResumedReason = "Resumed"
+	// RetriedReason 通过workflow.example.com/retry 重试Failed 的step
+	RetriedReason = "Retried"
+	// RollbackRequestedReason spec.desiredState 要求回滚workflow
+	RollbackRequestedReason = "RollbackRequested"
 )
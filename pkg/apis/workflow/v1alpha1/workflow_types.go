@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -35,6 +36,23 @@ type WorkflowSpec struct {
 	Parameters map[string]string `json:"parameters,omitempty"`
 	Callback   Callback          `json:"callback,omitempty"`
 	Steps      []WorkflowStep    `json:"steps,omitempty"`
+	// workflow 进入Running 后的最长运行时间，超时则回滚整个workflow，小于等于0 表示不限制
+	ActiveDeadlineSeconds int32 `json:"activeDeadlineSeconds,omitempty"`
+	// 同一个queue 内priority 高的workflow 先运行，等待时间越长有效优先级越高，避免低优先级的workflow 一直得不到运行
+	Priority int32 `json:"priority,omitempty"`
+	// 引用WorkflowTemplate/ClusterWorkflowTemplate，此时steps 来自模板，parameters 为模板声明的参数
+	WorkflowTemplateRef *WorkflowTemplateRef `json:"workflowTemplateRef,omitempty"`
+	// 为true 时暂停workflow：Pending 的step 不再进入Running，Running 的step 在下一次Run/重试前等待，已提交的异步任务继续轮询。
+	// 改回false 后从暂停处继续。暂停期间activeDeadlineSeconds 和step 的timeoutSeconds 仍然计时，回滚不受影响
+	Suspend bool `json:"suspend,omitempty"`
+	// 为RollBacked 时按依赖关系逆序回滚所有运行过的step，回滚完成后workflow 保持RollBacked，不会被删除。设置后不能撤销
+	DesiredState DesiredState `json:"desiredState,omitempty"`
+}
+
+type WorkflowTemplateRef struct {
+	Name string `json:"name"`
+	// 为true 时引用ClusterWorkflowTemplate，否则引用同namespace 的WorkflowTemplate
+	ClusterScope bool `json:"clusterScope,omitempty"`
 }
 
 type Callback struct { // 在workflow状态变更时发出回调
@@ -53,11 +71,45 @@ type DependOn struct { // 描述该step 依赖其他step的情况
 }
 
 type WorkflowStep struct {
-	Name         string     `json:"name,omitempty"`
-	DependOns    []DependOn `json:"dependOns,omitempty"`
-	StepTemplate StepSpec   `json:"stepTemplate,omitempty"`
+	Name      string     `json:"name,omitempty"`
+	DependOns []DependOn `json:"dependOns,omitempty"`
+	// dependOns 之间的关系，All 表示全部满足，Any 表示任意一个满足，Expression 表示dependExpression 为true
+	// +kubebuilder:default:=All
+	DependMode DependMode `json:"dependMode,omitempty"`
+	// dependMode 为Expression 时生效，按step name 引用对应的dependOn 是否满足，比如 ([mirror-a] || [mirror-b]) && init
+	DependExpression string `json:"dependExpression,omitempty"`
+	// 依赖满足后计算，为false 时step 进入Skipped，为空表示总是运行。
+	// 可以引用 [parameters.xx] 和 [attributes.xx]，比如 [parameters.env] == 'prod' && [attributes.needsMigration]
+	When string `json:"when,omitempty"`
+	// 静态列表，每一项展开为一个step，step parameters 中的item 为对应的项
+	WithItems []string `json:"withItems,omitempty"`
+	// 引用parameters.xx 或attributes.xx，其值为JSON 数组，每一项展开为一个step。引用attributes 时在dependOns 满足后展开
+	WithParam string `json:"withParam,omitempty"`
+	// withItems/withParam 展开的step 同时处于Running 的数量上限，小于等于0 表示不限制
+	// +kubebuilder:validation:Minimum=0
+	Parallelism int32 `json:"parallelism,omitempty"`
+	// 声明step 输出的attributes，只有声明的key 会出现在workflow 的stepOutputs 中，为空表示输出全部attributes
+	Outputs []string `json:"outputs,omitempty"`
+	// 把step 输出的attributes 导出到workflow 的attributes，key 为workflow attributes 的key，value 为step 输出的key。
+	// outputs、exportAttributes 都为空时导出全部attributes，后面的step 覆盖前面的，withItems/withParam 展开的step 不导出。
+	// 多个step 通过exportAttributes 向同一个key 写入不同的值时回滚workflow
+	ExportAttributes map[string]string `json:"exportAttributes,omitempty"`
+	StepTemplate     StepSpec          `json:"stepTemplate,omitempty"`
 }
 
+// DependMode
+// +kubebuilder:validation:Enum=All;Any;Expression
+type DependMode string
+
+const (
+	// DependAll dependOns 全部满足时运行
+	DependAll DependMode = "All"
+	// DependAny dependOns 任意一个满足时运行
+	DependAny DependMode = "Any"
+	// DependExpression dependExpression 为true 时运行
+	DependExpression DependMode = "Expression"
+)
+
 // RollbackPolicy
 // +kubebuilder:validation:Enum=Always;PreserveOnFailure
 type RollbackPolicy string
@@ -69,6 +121,15 @@ const (
 	PreserveOnFailure RollbackPolicy = "PreserveOnFailure"
 )
 
+// DesiredState
+// +kubebuilder:validation:Enum=RollBacked
+type DesiredState string
+
+const (
+	// DesiredRollBacked 回滚workflow 但保留workflow 对象
+	DesiredRollBacked DesiredState = "RollBacked"
+)
+
 // WorkflowPhase
 // +kubebuilder:validation:Enum=Pending;Running;Success;RollingBack;RollBacked;Failed
 type WorkflowPhase string
@@ -82,6 +143,11 @@ const (
 	WorkflowFailed      WorkflowPhase = "Failed"
 )
 
+const (
+	// WorkflowSuspended workflow 是否因为spec.suspend 暂停
+	WorkflowSuspended = "Suspended"
+)
+
 // WorkflowStatus defines the observed state of Workflow
 type WorkflowStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -97,8 +163,32 @@ type WorkflowStatus struct {
 	SyncError     string            `json:"syncError,omitempty"`
 	// 用于对比workflow status是否有变化
 	Hash string `json:"hash,omitempty"`
+	// 进入Running 的时间，用于计算是否超过activeDeadlineSeconds
+	StartedAt metav1.Time `json:"startedAt,omitempty"`
+	// 进入当前phase 的原因，比如DeadlineExceeded
+	Reason string `json:"reason,omitempty"`
+	// spec.priority 加上等待时间带来的提升，workflow 开始运行后不再变化
+	EffectivePriority int32 `json:"effectivePriority,omitempty"`
+	// withItems/withParam 展开的step 数量，key 为step name
+	StepItemCounts map[string]int32 `json:"stepItemCounts,omitempty"`
+	// 按step 区分的输出，key 为step name，withItems/withParam 展开的step 为 <step>-<index>
+	StepOutputs map[string]StepOutputs `json:"stepOutputs,omitempty"`
+	// 按step 汇总的结构化输出，为JSON object，key 与stepOutputs 相同，value 为step 的status.outputs
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Outputs *runtime.RawExtension `json:"outputs,omitempty"`
+	// workflowTemplateRef 在workflow 开始时解析出的spec 快照，之后模板的修改不影响该workflow
+	StoredSpec *WorkflowSpec `json:"storedSpec,omitempty"`
+	// workflow 的状态条件，比如Suspended
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// StepOutputs step 输出的attributes
+type StepOutputs map[string]string
+
 // Workflow is the Schema for the workflows API
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -108,6 +198,7 @@ type WorkflowStatus struct {
 // +kubebuilder:storageversion
 // +kubebuilder:resource:path=workflows,shortName=wf,scope=Namespaced
 // +kubebuilder:printcolumn:name="Queue",type="string",JSONPath=".spec.queue",description="workflow queue. "
+// +kubebuilder:printcolumn:name="Priority",type="integer",JSONPath=".status.effectivePriority",description="workflow effective priority. "
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="workflow phase. "
 // +kubebuilder:printcolumn:name="RunningSteps",type="integer",JSONPath=".status.stepPhases.Running",description="running step count"
 // +kubebuilder:printcolumn:name="SuccessSteps",type="integer",JSONPath=".status.stepPhases.Success",description="success step count"
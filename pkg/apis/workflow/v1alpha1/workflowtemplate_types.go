@@ -0,0 +1,90 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TemplateParameter 模板声明的输入参数
+type TemplateParameter struct {
+	Name string `json:"name"`
+	// 没有默认值的参数必须由workflow 设置
+	Default     *string `json:"default,omitempty"`
+	Description string  `json:"description,omitempty"`
+}
+
+// WorkflowTemplateSpec defines the desired state of WorkflowTemplate
+type WorkflowTemplateSpec struct {
+	// 声明的输入参数，workflow 只能设置声明过的参数
+	Inputs []TemplateParameter `json:"inputs,omitempty"`
+	// 可复用的workflow spec，其parameters 会被输入参数的默认值和workflow 的parameters 覆盖
+	Template WorkflowSpec `json:"template,omitempty"`
+}
+
+// WorkflowTemplate is the Schema for the workflowtemplates API
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +k8s:openapi-gen=true
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=workflowtemplates,shortName=wftmpl,scope=Namespaced
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="CreationTimestamp is a timestamp representing the server time when this object was created. "
+type WorkflowTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec WorkflowTemplateSpec `json:"spec,omitempty"`
+}
+
+// WorkflowTemplateList contains a list of WorkflowTemplate
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type WorkflowTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []WorkflowTemplate `json:"items"`
+}
+
+// ClusterWorkflowTemplate is the Schema for the clusterworkflowtemplates API, 所有namespace 的workflow 都可以引用
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +k8s:openapi-gen=true
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=clusterworkflowtemplates,shortName=cwftmpl,scope=Cluster
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="CreationTimestamp is a timestamp representing the server time when this object was created. "
+type ClusterWorkflowTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec WorkflowTemplateSpec `json:"spec,omitempty"`
+}
+
+// ClusterWorkflowTemplateList contains a list of ClusterWorkflowTemplate
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ClusterWorkflowTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterWorkflowTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&WorkflowTemplate{}, &WorkflowTemplateList{}, &ClusterWorkflowTemplate{}, &ClusterWorkflowTemplateList{})
+}
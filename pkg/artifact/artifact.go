@@ -0,0 +1,78 @@
+package artifact
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/utils/kube"
+)
+
+const (
+	// DataKey artifact 在ConfigMap/Secret 中的key
+	DataKey = "data"
+	// MaxSize ConfigMap/Secret 最大为1MiB，预留metadata 的空间
+	MaxSize = 1000 * 1024
+)
+
+// Storage 存放artifact 的后端，artifact 以name 区分，owner 删除时一并删除
+type Storage interface {
+	Kind() v1alpha1.ArtifactStorage
+	Put(ctx context.Context, namespace, name string, data []byte, owner client.Object) error
+	Get(ctx context.Context, namespace, name string) ([]byte, error)
+}
+
+// NewStorage 按kind 创建Storage，为空时使用ConfigMap
+func NewStorage(kind v1alpha1.ArtifactStorage, c client.Client) (Storage, error) {
+	switch kind {
+	case "", v1alpha1.ConfigMapArtifactStorage:
+		return &configMapStorage{client: c}, nil
+	case v1alpha1.SecretArtifactStorage:
+		return &secretStorage{client: c}, nil
+	}
+	return nil, fmt.Errorf("unsupported artifact storage %s", kind)
+}
+
+// ObjectName 存放artifact 的对象名称，artifact 名称不能包含点，所以不同step 的artifact 不会重名
+func ObjectName(stepName, artifactName string) string {
+	return fmt.Sprintf("%s.%s", stepName, artifactName)
+}
+
+type configMapStorage struct {
+	client client.Client
+}
+
+func (s *configMapStorage) Kind() v1alpha1.ArtifactStorage {
+	return v1alpha1.ConfigMapArtifactStorage
+}
+
+func (s *configMapStorage) Put(ctx context.Context, namespace, name string, data []byte, owner client.Object) error {
+	ref := &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: DataKey}
+	return kube.CreateOrUpdateConfigMapV2(ctx, s.client, namespace, ref, data, owner)
+}
+
+func (s *configMapStorage) Get(ctx context.Context, namespace, name string) ([]byte, error) {
+	ref := &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: DataKey}
+	return kube.GetConfigMapV2(ctx, s.client, namespace, ref)
+}
+
+type secretStorage struct {
+	client client.Client
+}
+
+func (s *secretStorage) Kind() v1alpha1.ArtifactStorage {
+	return v1alpha1.SecretArtifactStorage
+}
+
+func (s *secretStorage) Put(ctx context.Context, namespace, name string, data []byte, owner client.Object) error {
+	ref := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: DataKey}
+	return kube.CreateOrUpdateSecretV2(ctx, s.client, namespace, corev1.SecretTypeOpaque, ref, data, owner)
+}
+
+func (s *secretStorage) Get(ctx context.Context, namespace, name string) ([]byte, error) {
+	ref := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: DataKey}
+	return kube.GetSecretV2(ctx, s.client, namespace, ref)
+}
@@ -0,0 +1,48 @@
+package artifact
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+func TestStorage(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+	owner := &v1alpha1.Workflow{}
+	owner.Name = "example"
+	owner.Namespace = "default"
+	owner.UID = "uid"
+	owner.SetGroupVersionKind(v1alpha1.GroupVersion.WithKind("Workflow"))
+
+	ctx := context.Background()
+	for _, kind := range []v1alpha1.ArtifactStorage{v1alpha1.ConfigMapArtifactStorage, v1alpha1.SecretArtifactStorage} {
+		s, err := NewStorage(kind, fake.NewClientBuilder().WithScheme(scheme).Build())
+		if err != nil || s.Kind() != kind {
+			t.Fatalf("new %s storage error: %v", kind, err)
+		}
+		if _, err = s.Get(ctx, "default", "example-a.manifest"); err == nil {
+			t.Errorf("%s: expect error for missing artifact", kind)
+		}
+		if err = s.Put(ctx, "default", "example-a.manifest", []byte("v1"), owner); err != nil {
+			t.Fatalf("%s: put error: %v", kind, err)
+		}
+		// 再次写入时覆盖
+		if err = s.Put(ctx, "default", "example-a.manifest", []byte("v2"), owner); err != nil {
+			t.Fatalf("%s: put error: %v", kind, err)
+		}
+		data, err := s.Get(ctx, "default", "example-a.manifest")
+		if err != nil || string(data) != "v2" {
+			t.Errorf("%s: expect v2, got %s %v", kind, data, err)
+		}
+	}
+	if _, err := NewStorage("S3", nil); err == nil {
+		t.Errorf("expect error for unsupported storage")
+	}
+}
@@ -7,4 +7,16 @@ const (
 	WorkflowPrefix         = "workflow.example.com"
 	FinalizersWorkflow     = WorkflowPrefix + "/workflow-finalizers"
 	DefaultRequeueDuration = 10 * time.Second
+	// AnnotationSkipRollback 为true 时删除成功的workflow 不回滚step，比如CronWorkflow 清理历史workflow
+	AnnotationSkipRollback = WorkflowPrefix + "/skip-rollback"
+	// AnnotationRetry workflow Failed 后设置，重试Failed 的step，处理后由controller 删除
+	AnnotationRetry = WorkflowPrefix + "/retry"
+	// AnnotationApproval step 上为approved 或rejected 时通过或拒绝approval step
+	AnnotationApproval = WorkflowPrefix + "/approval"
+	// AnnotationApprover 设置AnnotationApproval 的用户，由webhook 根据请求记录
+	AnnotationApprover = WorkflowPrefix + "/approver"
+	// ApprovalAnnotationPrefix workflow 上的 approval.workflow.example.com/<step> 通过或拒绝对应的approval step
+	ApprovalAnnotationPrefix = "approval." + WorkflowPrefix + "/"
+	// ApproverAnnotationPrefix workflow 上设置 approval.workflow.example.com/<step> 的用户
+	ApproverAnnotationPrefix = "approver." + WorkflowPrefix + "/"
 )
@@ -2,6 +2,7 @@ package manager
 
 import (
 	"github.com/qiankunli/workflow/pkg/options"
+	"github.com/qiankunli/workflow/pkg/utils/cancel"
 	"github.com/qiankunli/workflow/pkg/utils/mutex"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -12,6 +13,8 @@ type ControllerContext struct {
 	Config        *options.Config
 	StepMutex     mutex.GroupMutex
 	WorkflowMutex mutex.GroupMutex
+	// 按workflow 分组记录运行中的step ctx，workflow 删除时cancel
+	StepCanceler cancel.GroupCanceler
 
 	KubeClient kubernetes.Interface
 }
@@ -28,5 +31,6 @@ func NewControllerContext(restConfig *rest.Config, cfg *options.Config) (*Contro
 		KubeClient:    ctrlClient,
 		WorkflowMutex: mutex.NewGroupMutex(),
 		StepMutex:     mutex.NewGroupMutex(),
+		StepCanceler:  cancel.NewGroupCanceler(),
 	}, nil
 }
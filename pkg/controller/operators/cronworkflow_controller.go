@@ -0,0 +1,304 @@
+package operators
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sutilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/constants"
+	"github.com/qiankunli/workflow/pkg/controller/manager"
+	"github.com/qiankunli/workflow/pkg/schedule"
+	"github.com/qiankunli/workflow/pkg/utils/kube"
+)
+
+const (
+	// cronWorkflowLabel CronWorkflow 创建的workflow 上记录CronWorkflow 的名称
+	cronWorkflowLabel = "cron-workflow"
+	// scheduledTimeAnnotation CronWorkflow 创建workflow 时对应的调度时间
+	scheduledTimeAnnotation = constants.WorkflowPrefix + "/scheduled-time"
+)
+
+type cronWorkflowReconciler struct {
+	client   client.Client
+	log      logr.Logger
+	recorder record.EventRecorder
+	now      func() time.Time
+}
+
+// RegisterCronWorkflowReconciler ...
+func RegisterCronWorkflowReconciler(mgr ctrl.Manager, controllerCtx *manager.ControllerContext) error {
+	const name = "cronworkflow-controller"
+
+	r := &cronWorkflowReconciler{
+		client:   mgr.GetClient(),
+		log:      ctrl.LoggerFrom(context.Background()).WithName(name),
+		recorder: mgr.GetEventRecorderFor(name),
+		now:      time.Now,
+	}
+
+	_, err := ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			CacheSyncTimeout:        controllerCtx.Config.ControllerConfig.SyncTimeout.Duration,
+			MaxConcurrentReconciles: controllerCtx.Config.ControllerConfig.Concurrency,
+		}).
+		For(&v1alpha1.CronWorkflow{}).
+		Owns(&v1alpha1.Workflow{}).
+		Named(name).
+		Build(r)
+
+	if err != nil {
+		return fmt.Errorf("failed to set up with manager: %w", err)
+	}
+	r.log.Info("succeeded to set up with manager")
+	return nil
+}
+
+// Reconcile ...
+func (r *cronWorkflowReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res ctrl.Result, reterr error) {
+	log := r.log.WithValues("name", req.Name)
+	defer func() {
+		if p := recover(); p != nil {
+			log.Error(errors.Errorf("panic error: %+v", p), "Panic error")
+			debug.PrintStack()
+		}
+	}()
+
+	ctx = ctrl.LoggerInto(ctx, log)
+	log.V(4).Info("cron workflow start reconcile")
+
+	cronWorkflow := &v1alpha1.CronWorkflow{}
+	if err := r.client.Get(ctx, req.NamespacedName, cronWorkflow); err != nil {
+		if k8sapierrors.IsNotFound(err) {
+			log.Info("cron workflow has been deleted")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "failed to get cron workflow")
+		return ctrl.Result{}, err
+	}
+	// 创建的workflow 通过ownerReference 级联删除
+	if !cronWorkflow.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	patchHelper, err := kube.NewHelper(cronWorkflow, r.client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, cronWorkflow); err != nil {
+			reterr = k8sutilerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	workflows, err := r.getWorkflowsForCronWorkflow(ctx, cronWorkflow)
+	if err != nil {
+		log.Error(err, "failed to list workflows of cron workflow")
+		return ctrl.Result{}, err
+	}
+	active, successful, failed := classifyWorkflows(workflows)
+	syncCronWorkflowStatus(cronWorkflow, active, successful)
+	if err = r.cleanupHistory(ctx, cronWorkflow, successful, failed); err != nil {
+		log.Error(err, "failed to cleanup history workflows")
+	}
+	if cronWorkflow.Spec.Suspend {
+		log.V(4).Info("cron workflow is suspended")
+		return ctrl.Result{}, nil
+	}
+	sched, err := schedule.Parse(cronWorkflow.Spec.Schedule, cronWorkflow.Spec.Timezone)
+	if err != nil {
+		// 等待用户修改spec
+		r.recorder.Eventf(cronWorkflow, corev1.EventTypeWarning, v1alpha1.SpecWrongReason, "unparseable schedule '%s': %v",
+			cronWorkflow.Spec.Schedule, err)
+		return ctrl.Result{}, nil
+	}
+
+	now := r.now()
+	requeueAfter := ctrl.Result{RequeueAfter: sched.Next(now).Sub(now)}
+	scheduledTime, err := schedule.MostRecent(sched, earliestScheduleTime(cronWorkflow, now), now)
+	if err != nil {
+		// 错过太多次调度，从当前时间重新开始
+		r.recorder.Eventf(cronWorkflow, corev1.EventTypeWarning, v1alpha1.SkippedScheduleReason, "%v", err)
+		cronWorkflow.Status.LastScheduleTime = &metav1.Time{Time: now}
+		return requeueAfter, nil
+	}
+	if scheduledTime.IsZero() {
+		return requeueAfter, nil
+	}
+
+	switch cronWorkflow.Spec.ConcurrencyPolicy {
+	case v1alpha1.ForbidConcurrent:
+		if len(active) > 0 {
+			// 不更新lastScheduleTime，上一个workflow 在startingDeadlineSeconds 内结束时还会创建本次的workflow
+			log.V(4).Info("skip schedule because of running workflows", "scheduledTime", scheduledTime)
+			r.recorder.Eventf(cronWorkflow, corev1.EventTypeNormal, v1alpha1.SkippedScheduleReason,
+				"skip schedule at %s, %d workflows are still running", scheduledTime.Format(time.RFC3339), len(active))
+			return requeueAfter, nil
+		}
+	case v1alpha1.ReplaceConcurrent:
+		for i := range active {
+			// 删除workflow 会回滚其step
+			if err = r.client.Delete(ctx, &active[i]); client.IgnoreNotFound(err) != nil {
+				log.Error(err, "failed to delete active workflow", "workflow", active[i].Name)
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	workflow := newCronChildWorkflow(cronWorkflow, scheduledTime)
+	if err = r.client.Create(ctx, workflow); err != nil && !k8sapierrors.IsAlreadyExists(err) {
+		log.Error(err, "failed to create workflow", "workflow", workflow.Name)
+		return ctrl.Result{}, err
+	}
+	cronWorkflow.Status.LastScheduleTime = &metav1.Time{Time: scheduledTime}
+	cronWorkflow.Status.Active = append(cronWorkflow.Status.Active, workflowReference(workflow))
+	r.recorder.Eventf(cronWorkflow, corev1.EventTypeNormal, v1alpha1.ScheduledReason, "created workflow '%s'", workflow.Name)
+	return requeueAfter, nil
+}
+
+func (r *cronWorkflowReconciler) getWorkflowsForCronWorkflow(ctx context.Context, cronWorkflow *v1alpha1.CronWorkflow) ([]v1alpha1.Workflow, error) {
+	workflowList := &v1alpha1.WorkflowList{}
+	err := r.client.List(ctx, workflowList, client.InNamespace(cronWorkflow.Namespace),
+		client.MatchingLabels{cronWorkflowLabel: cronWorkflow.Name})
+	if err != nil {
+		return nil, err
+	}
+	workflows := make([]v1alpha1.Workflow, 0, len(workflowList.Items))
+	for _, workflow := range workflowList.Items {
+		if metav1.IsControlledBy(&workflow, cronWorkflow) {
+			workflows = append(workflows, workflow)
+		}
+	}
+	// 按创建时间排序，清理历史时先删除最早的workflow
+	sort.SliceStable(workflows, func(i, j int) bool {
+		return workflows[i].CreationTimestamp.Before(&workflows[j].CreationTimestamp)
+	})
+	return workflows, nil
+}
+
+// classifyWorkflows 按phase 把workflow 分为运行中、成功和失败(RollBacked、Failed)
+func classifyWorkflows(workflows []v1alpha1.Workflow) (active, successful, failed []v1alpha1.Workflow) {
+	for _, workflow := range workflows {
+		switch workflow.Status.Phase {
+		case v1alpha1.WorkflowSuccess:
+			successful = append(successful, workflow)
+		case v1alpha1.WorkflowRollBacked, v1alpha1.WorkflowFailed:
+			failed = append(failed, workflow)
+		default:
+			active = append(active, workflow)
+		}
+	}
+	return
+}
+
+func syncCronWorkflowStatus(cronWorkflow *v1alpha1.CronWorkflow, active, successful []v1alpha1.Workflow) {
+	cronWorkflow.Status.Active = nil
+	for i := range active {
+		cronWorkflow.Status.Active = append(cronWorkflow.Status.Active, workflowReference(&active[i]))
+	}
+	for _, workflow := range successful {
+		scheduledTime, err := time.Parse(time.RFC3339, workflow.Annotations[scheduledTimeAnnotation])
+		if err != nil {
+			continue
+		}
+		if last := cronWorkflow.Status.LastSuccessfulTime; last == nil || last.Time.Before(scheduledTime) {
+			cronWorkflow.Status.LastSuccessfulTime = &metav1.Time{Time: scheduledTime}
+		}
+	}
+}
+
+// cleanupHistory 删除超过历史数量限制的workflow，成功的workflow 带上skip-rollback 注解，删除时不回滚
+func (r *cronWorkflowReconciler) cleanupHistory(ctx context.Context, cronWorkflow *v1alpha1.CronWorkflow, successful, failed []v1alpha1.Workflow) error {
+	var errs []error
+	for _, workflow := range overHistoryLimit(successful, cronWorkflow.Spec.SuccessfulHistoryLimit) {
+		if workflow.Annotations[constants.AnnotationSkipRollback] != "true" {
+			patch := client.MergeFrom(workflow.DeepCopy())
+			if workflow.Annotations == nil {
+				workflow.Annotations = map[string]string{}
+			}
+			workflow.Annotations[constants.AnnotationSkipRollback] = "true"
+			if err := r.client.Patch(ctx, &workflow, patch); err != nil {
+				errs = append(errs, client.IgnoreNotFound(err))
+				continue
+			}
+		}
+		errs = append(errs, r.deleteWorkflow(ctx, &workflow))
+	}
+	for _, workflow := range overHistoryLimit(failed, cronWorkflow.Spec.FailedHistoryLimit) {
+		errs = append(errs, r.deleteWorkflow(ctx, &workflow))
+	}
+	return k8sutilerrors.NewAggregate(errs)
+}
+
+func (r *cronWorkflowReconciler) deleteWorkflow(ctx context.Context, workflow *v1alpha1.Workflow) error {
+	if !workflow.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	return client.IgnoreNotFound(r.client.Delete(ctx, workflow))
+}
+
+// overHistoryLimit 返回超过数量限制的最早的workflow，limit 为空时不限制
+func overHistoryLimit(workflows []v1alpha1.Workflow, limit *int32) []v1alpha1.Workflow {
+	if limit == nil || len(workflows) <= int(*limit) {
+		return nil
+	}
+	return workflows[:len(workflows)-int(*limit)]
+}
+
+// earliestScheduleTime 从上次调度时间(没有时为创建时间)开始计算错过的调度，超过startingDeadlineSeconds 的调度不再补上
+func earliestScheduleTime(cronWorkflow *v1alpha1.CronWorkflow, now time.Time) time.Time {
+	earliest := cronWorkflow.CreationTimestamp.Time
+	if cronWorkflow.Status.LastScheduleTime != nil {
+		earliest = cronWorkflow.Status.LastScheduleTime.Time
+	}
+	if deadline := cronWorkflow.Spec.StartingDeadlineSeconds; deadline != nil {
+		if limit := now.Add(-time.Duration(*deadline) * time.Second); limit.After(earliest) {
+			earliest = limit
+		}
+	}
+	return earliest
+}
+
+// newCronChildWorkflow workflow 名称为 <cronWorkflow>-<调度时间的unix 分钟数>，同一个调度时间只会创建一个workflow
+func newCronChildWorkflow(cronWorkflow *v1alpha1.CronWorkflow, scheduledTime time.Time) *v1alpha1.Workflow {
+	workflow := &v1alpha1.Workflow{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cronWorkflow.Namespace,
+			Name:      fmt.Sprintf("%s-%d", cronWorkflow.Name, scheduledTime.Unix()/60),
+			Labels: map[string]string{
+				cronWorkflowLabel: cronWorkflow.Name,
+			},
+			Annotations: map[string]string{
+				scheduledTimeAnnotation: scheduledTime.UTC().Format(time.RFC3339),
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cronWorkflow, v1alpha1.GroupVersion.WithKind("CronWorkflow")),
+			},
+		},
+		Spec: *cronWorkflow.Spec.WorkflowSpec.DeepCopy(),
+	}
+	return workflow
+}
+
+func workflowReference(workflow *v1alpha1.Workflow) corev1.ObjectReference {
+	return corev1.ObjectReference{
+		APIVersion: v1alpha1.GroupVersion.String(),
+		Kind:       "Workflow",
+		Namespace:  workflow.Namespace,
+		Name:       workflow.Name,
+		UID:        workflow.UID,
+	}
+}
@@ -0,0 +1,154 @@
+package operators
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/utils"
+)
+
+var cronTestCreated = time.Date(2023, 6, 1, 0, 30, 0, 0, time.UTC)
+
+func newTestCronWorkflow(policy v1alpha1.ConcurrencyPolicy) *v1alpha1.CronWorkflow {
+	cronWorkflow := &v1alpha1.CronWorkflow{Spec: v1alpha1.CronWorkflowSpec{
+		Schedule:          "0 * * * *",
+		Timezone:          "UTC",
+		ConcurrencyPolicy: policy,
+		WorkflowSpec:      v1alpha1.WorkflowSpec{Steps: []v1alpha1.WorkflowStep{{Name: "a"}}},
+	}}
+	cronWorkflow.Name = "nightly"
+	cronWorkflow.Namespace = "default"
+	cronWorkflow.CreationTimestamp = metav1.NewTime(cronTestCreated)
+	return cronWorkflow
+}
+
+func newTestCronChildWorkflow(cronWorkflow *v1alpha1.CronWorkflow, scheduledTime time.Time, phase v1alpha1.WorkflowPhase) *v1alpha1.Workflow {
+	workflow := newCronChildWorkflow(cronWorkflow, scheduledTime)
+	workflow.CreationTimestamp = metav1.NewTime(scheduledTime)
+	workflow.Status.Phase = phase
+	return workflow
+}
+
+func newTestCronWorkflowReconciler(now time.Time, objs ...client.Object) *cronWorkflowReconciler {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	return &cronWorkflowReconciler{
+		client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		log:      logr.Discard(),
+		recorder: record.NewFakeRecorder(10),
+		now:      func() time.Time { return now },
+	}
+}
+
+func reconcileTestCronWorkflow(t *testing.T, r *cronWorkflowReconciler) (*v1alpha1.CronWorkflow, []v1alpha1.Workflow) {
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "default", Name: "nightly"}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("reconcile error: %v", err)
+	}
+	cronWorkflow := &v1alpha1.CronWorkflow{}
+	if err := r.client.Get(ctx, key, cronWorkflow); err != nil {
+		t.Fatalf("get cron workflow error: %v", err)
+	}
+	workflows, err := r.getWorkflowsForCronWorkflow(ctx, cronWorkflow)
+	if err != nil {
+		t.Fatalf("list workflows error: %v", err)
+	}
+	return cronWorkflow, workflows
+}
+
+func TestCronWorkflowSchedule(t *testing.T) {
+	cronWorkflow := newTestCronWorkflow(v1alpha1.AllowConcurrent)
+	// 错过了1点和2点，只补最近的一次
+	now := cronTestCreated.Add(2 * time.Hour)
+	r := newTestCronWorkflowReconciler(now, cronWorkflow)
+	cronWorkflow, workflows := reconcileTestCronWorkflow(t, r)
+	scheduledTime := time.Date(2023, 6, 1, 2, 0, 0, 0, time.UTC)
+	if len(workflows) != 1 || workflows[0].Name != newCronChildWorkflow(cronWorkflow, scheduledTime).Name {
+		t.Fatalf("expect workflow scheduled at %s, got %v", scheduledTime, workflows)
+	}
+	if workflows[0].Labels[cronWorkflowLabel] != "nightly" || len(workflows[0].Spec.Steps) != 1 {
+		t.Errorf("unexpected workflow %+v", workflows[0])
+	}
+	if last := cronWorkflow.Status.LastScheduleTime; last == nil || !last.Time.Equal(scheduledTime) {
+		t.Errorf("expect lastScheduleTime %s, got %v", scheduledTime, last)
+	}
+	if len(cronWorkflow.Status.Active) != 1 {
+		t.Errorf("expect 1 active workflow, got %v", cronWorkflow.Status.Active)
+	}
+
+	// 同一个调度时间不会重复创建
+	if _, workflows = reconcileTestCronWorkflow(t, r); len(workflows) != 1 {
+		t.Errorf("expect no new workflow, got %d", len(workflows))
+	}
+}
+
+func TestCronWorkflowStartingDeadline(t *testing.T) {
+	cronWorkflow := newTestCronWorkflow(v1alpha1.AllowConcurrent)
+	cronWorkflow.Spec.StartingDeadlineSeconds = utils.ToInt64P(60)
+	r := newTestCronWorkflowReconciler(cronTestCreated.Add(40*time.Minute), cronWorkflow)
+	if _, workflows := reconcileTestCronWorkflow(t, r); len(workflows) != 0 {
+		t.Errorf("expect schedule missed, got %d workflows", len(workflows))
+	}
+}
+
+func TestCronWorkflowConcurrencyPolicy(t *testing.T) {
+	now := cronTestCreated.Add(90 * time.Minute)
+	previous := time.Date(2023, 6, 1, 1, 0, 0, 0, time.UTC)
+	cases := []struct {
+		policy v1alpha1.ConcurrencyPolicy
+		expect int
+	}{
+		{policy: v1alpha1.AllowConcurrent, expect: 2},
+		{policy: v1alpha1.ForbidConcurrent, expect: 1},
+		{policy: v1alpha1.ReplaceConcurrent, expect: 1},
+	}
+	for _, c := range cases {
+		cronWorkflow := newTestCronWorkflow(c.policy)
+		cronWorkflow.Status.LastScheduleTime = &metav1.Time{Time: previous}
+		running := newTestCronChildWorkflow(cronWorkflow, previous, v1alpha1.WorkflowRunning)
+		r := newTestCronWorkflowReconciler(now.Add(time.Hour), cronWorkflow, running)
+		_, workflows := reconcileTestCronWorkflow(t, r)
+		if len(workflows) != c.expect {
+			t.Errorf("%s: expect %d workflows, got %d", c.policy, c.expect, len(workflows))
+			continue
+		}
+		// Replace 删除了运行中的workflow
+		if c.policy == v1alpha1.ReplaceConcurrent && workflows[0].Name == running.Name {
+			t.Errorf("%s: expect running workflow replaced", c.policy)
+		}
+	}
+}
+
+func TestCronWorkflowHistoryLimit(t *testing.T) {
+	cronWorkflow := newTestCronWorkflow(v1alpha1.AllowConcurrent)
+	cronWorkflow.Spec.Suspend = true
+	cronWorkflow.Spec.SuccessfulHistoryLimit = utils.ToInt32P(1)
+	cronWorkflow.Spec.FailedHistoryLimit = utils.ToInt32P(0)
+	objs := []client.Object{cronWorkflow}
+	for i := 1; i <= 3; i++ {
+		scheduledTime := cronTestCreated.Add(time.Duration(i) * time.Hour)
+		objs = append(objs, newTestCronChildWorkflow(cronWorkflow, scheduledTime, v1alpha1.WorkflowSuccess))
+	}
+	objs = append(objs, newTestCronChildWorkflow(cronWorkflow, cronTestCreated.Add(4*time.Hour), v1alpha1.WorkflowRollBacked))
+	r := newTestCronWorkflowReconciler(cronTestCreated.Add(5*time.Hour), objs...)
+	cronWorkflow, workflows := reconcileTestCronWorkflow(t, r)
+	// 保留最近一次成功的workflow
+	if len(workflows) != 1 || workflows[0].Name != objs[3].GetName() {
+		t.Errorf("expect only the latest successful workflow kept, got %d", len(workflows))
+	}
+	if last := cronWorkflow.Status.LastSuccessfulTime; last == nil || !last.Time.Equal(cronTestCreated.Add(3*time.Hour)) {
+		t.Errorf("expect lastSuccessfulTime of the latest successful workflow, got %v", last)
+	}
+}
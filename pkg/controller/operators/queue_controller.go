@@ -3,12 +3,14 @@ package operators
 import (
 	"context"
 	"fmt"
+	"math"
 	"runtime/debug"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
-	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	k8sutilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
@@ -30,8 +32,10 @@ type queueReconciler struct {
 	controllerCtx   *manager.ControllerContext
 	queueStrategy   controller2.QueueStrategy
 	maxRunningCount int
-	log             logr.Logger
-	recorder        record.EventRecorder
+	// pending workflow 每等待一个周期有效优先级加1
+	priorityAgingPeriod time.Duration
+	log                 logr.Logger
+	recorder            record.EventRecorder
 }
 
 // RegisterQueueReconciler ...
@@ -45,6 +49,8 @@ func RegisterQueueReconciler(mgr ctrl.Manager, controllerCtx *manager.Controller
 		recorder:        mgr.GetEventRecorderFor(name),
 		maxRunningCount: controllerCtx.Config.ControllerConfig.Queue.MaxRunningCount,
 		queueStrategy:   controllerCtx.Config.ControllerConfig.Queue.Strategy,
+
+		priorityAgingPeriod: controllerCtx.Config.ControllerConfig.Queue.PriorityAgingPeriod.Duration,
 	}
 
 	_, err := ctrl.NewControllerManagedBy(mgr).
@@ -81,23 +87,49 @@ func (r *queueReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 		log.Error(err, "failed to list workflow")
 		return ctrl.Result{}, err
 	}
-	if len(workflowList.Items) > r.maxRunningCount {
-		log.V(4).Info(fmt.Sprintf("running workflow limit exceeded maxRunning count: %d", r.maxRunningCount))
+	queueList := &v1alpha1.QueueList{}
+	if err := r.client.List(context.Background(), queueList); err != nil {
+		log.Error(err, "failed to list queue")
+		return ctrl.Result{}, err
+	}
+	queues := map[string]*v1alpha1.Queue{}
+	for i := range queueList.Items {
+		queues[queueList.Items[i].Name] = &queueList.Items[i]
+	}
+	priority := workflowPriority{agingPeriod: r.priorityAgingPeriod, now: time.Now()}
+	current := findWorkflow(workflowList.Items, req.NamespacedName)
+	if current != nil {
+		if err := r.updateEffectivePriority(ctx, current, priority); err != nil {
+			log.Error(err, "failed to update workflow effective priority")
+		}
+	}
+	queueStates := collectQueueStates(workflowList.Items, priority)
+	if err := r.updateQueueStatus(ctx, queueList.Items, queueStates); err != nil {
+		log.Error(err, "failed to update queue status")
+	}
+	// 只有运行中、回滚中的workflow 占用运行名额
+	if runningCount := countRunningWorkflow(workflowList.Items); runningCount >= r.maxRunningCount {
+		log.V(4).Info(fmt.Sprintf("running workflow limit exceeded maxRunning count: %d, running count: %d", r.maxRunningCount, runningCount))
 		return ctrl.Result{RequeueAfter: constants.DefaultRequeueDuration}, nil
 	}
 	var workflow *v1alpha1.Workflow
 	if r.queueStrategy == controller2.FIFO {
-		workflow = &v1alpha1.Workflow{}
-		if err := r.client.Get(ctx, req.NamespacedName, workflow); err != nil {
-			if k8sapierrors.IsNotFound(err) {
-				klog.InfoS("workflow has been deleted")
-				return ctrl.Result{}, err
-			}
-			log.Error(err, "failed to get workflow")
-			return ctrl.Result{}, err
+		if current == nil {
+			klog.InfoS("workflow has been deleted")
+			return ctrl.Result{}, nil
+		}
+		workflow = current
+		queue := workflow.Spec.Queue
+		if isPendingWorkflow(workflow) && !canRunInQueue(queues[queue], queueStates[queue].running()) {
+			log.V(4).Info(fmt.Sprintf("queue %s is paused or exceeded maxRunning count", queue))
+			return ctrl.Result{RequeueAfter: constants.DefaultRequeueDuration}, nil
 		}
-	} else if r.queueStrategy == controller2.FIFO {
-		workflow = findNeedRunningWorkflow(workflowList)
+		// 同一个queue 内有效优先级更高或者更早创建的workflow 先运行
+		if isPendingWorkflow(workflow) && queueStates[queue].nextPending != workflow {
+			return ctrl.Result{RequeueAfter: constants.DefaultRequeueDuration}, nil
+		}
+	} else if r.queueStrategy == controller2.FAIR {
+		workflow = findNeedRunningWorkflow(workflowList.Items, queues, priority)
 	}
 	if workflow != nil {
 		patchHelper, err := kube.NewHelper(workflow, r.client)
@@ -109,7 +141,8 @@ func (r *queueReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 				reterr = k8sutilerrors.NewAggregate([]error{reterr, err})
 			}
 		}()
-		if workflow.Status.Phase == v1alpha1.WorkflowPending {
+		if isPendingWorkflow(workflow) {
+			workflow.Status.EffectivePriority = priority.effective(workflow)
 			workflow.Status.Phase = v1alpha1.WorkflowRunning
 			log.V(4).Info(fmt.Sprintf("trigger queue %s workflow %s running", workflow.Spec.Queue, workflow.Name))
 			r.recorder.Eventf(workflow, corev1.EventTypeNormal, v1alpha1.PhaseChangeReason, "'%v' => '%s'",
@@ -119,44 +152,210 @@ func (r *queueReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 	return ctrl.Result{RequeueAfter: constants.DefaultRequeueDuration}, nil
 }
 
-func findNeedRunningWorkflow(workflowList *v1alpha1.WorkflowList) *v1alpha1.Workflow {
-	queueWorkflowRunningCount := map[string]int{}
-	queueWorkflowPendingCount := map[string]int{}
-	queueWorkflow := map[string][]v1alpha1.Workflow{}
-	for _, workflow := range workflowList.Items {
-		if wfList, ok := queueWorkflow[workflow.Spec.Queue]; ok {
-			wfList = append(wfList, workflow)
-		} else {
-			queueWorkflow[workflow.Spec.Queue] = []v1alpha1.Workflow{workflow}
-		}
-		if workflow.Status.Phase == v1alpha1.WorkflowRunning {
-			queueWorkflowRunningCount[workflow.Spec.Queue]++
-		} else if workflow.Status.Phase == v1alpha1.WorkflowPending {
-			queueWorkflowPendingCount[workflow.Spec.Queue]++
-		}
-	}
-	// 找到running 数量最少 且有pending workflow 的queue
-	needRunningQueue := ""
-	minWorkflowRunningCount := 1
-	for queue, _ := range queueWorkflow {
-		if queueWorkflowPendingCount[queue] > 0 {
-			if queueWorkflowRunningCount[queue] < minWorkflowRunningCount {
-				minWorkflowRunningCount = queueWorkflowRunningCount[queue]
-				needRunningQueue = queue
-			}
+var isPendingWorkflow = func(workflow *v1alpha1.Workflow) bool {
+	// 正在删除的workflow 不再进入运行
+	if !workflow.DeletionTimestamp.IsZero() {
+		return false
+	}
+	return workflow.Status.Phase == "" || workflow.Status.Phase == v1alpha1.WorkflowPending
+}
+
+var isRunningWorkflow = func(workflow *v1alpha1.Workflow) bool {
+	return workflow.Status.Phase == v1alpha1.WorkflowRunning || workflow.Status.Phase == v1alpha1.WorkflowRollingBack
+}
+
+func countRunningWorkflow(workflows []v1alpha1.Workflow) int {
+	count := 0
+	for i := range workflows {
+		if isRunningWorkflow(&workflows[i]) {
+			count++
+		}
+	}
+	return count
+}
+
+// queueState 某个queue 下workflow 的运行情况
+type queueState struct {
+	runningCount int
+	pendingCount int
+	nextPending  *v1alpha1.Workflow
+}
+
+func (s *queueState) running() int {
+	if s == nil {
+		return 0
+	}
+	return s.runningCount
+}
+
+// collectQueueStates 按spec.queue 统计running、pending 的workflow，并记录每个queue 下一个需要运行的pending workflow
+func collectQueueStates(workflows []v1alpha1.Workflow, priority workflowPriority) map[string]*queueState {
+	states := map[string]*queueState{}
+	for i := range workflows {
+		workflow := &workflows[i]
+		running, pending := isRunningWorkflow(workflow), isPendingWorkflow(workflow)
+		if !running && !pending {
+			continue
+		}
+		state, ok := states[workflow.Spec.Queue]
+		if !ok {
+			state = &queueState{}
+			states[workflow.Spec.Queue] = state
+		}
+		if running {
+			state.runningCount++
+			continue
 		}
+		state.pendingCount++
+		if state.nextPending == nil || priority.before(workflow, state.nextPending) {
+			state.nextPending = workflow
+		}
+	}
+	return states
+}
+
+// canRunInQueue queue 未暂停且没有超过maxRunning 时可以启动新的workflow，没有对应Queue 对象的queue 不做限制
+func canRunInQueue(queue *v1alpha1.Queue, runningCount int) bool {
+	if queue == nil {
+		return true
+	}
+	if queue.Spec.Paused {
+		return false
+	}
+	return queue.Spec.MaxRunning <= 0 || int32(runningCount) < queue.Spec.MaxRunning
+}
+
+func queuePriority(queue *v1alpha1.Queue) int32 {
+	if queue == nil {
+		return 0
 	}
+	return queue.Spec.Priority
+}
+
+func queueWeight(queue *v1alpha1.Queue) int32 {
+	if queue == nil || queue.Spec.Weight <= 0 {
+		return 1
+	}
+	return queue.Spec.Weight
+}
+
+// findNeedRunningWorkflow 在可以启动新workflow 的queue 中按fairBefore 选出一个，返回其下一个需要运行的workflow，没有时返回nil
+func findNeedRunningWorkflow(workflows []v1alpha1.Workflow, queues map[string]*v1alpha1.Queue, priority workflowPriority) *v1alpha1.Workflow {
 	var needRunningWorkflow *v1alpha1.Workflow
-	if len(needRunningQueue) > 0 {
-		needRunningWorkflows := queueWorkflow[needRunningQueue]
-		minCreateTime := needRunningWorkflows[0].CreationTimestamp
-		needRunningWorkflow = &needRunningWorkflows[0]
-		for _, workflow := range needRunningWorkflows {
-			if workflow.CreationTimestamp.Before(&minCreateTime) {
-				minCreateTime = workflow.CreationTimestamp
-				needRunningWorkflow = &workflow
-			}
+	var needRunningQueue *v1alpha1.Queue
+	var needRunningState *queueState
+	for name, state := range collectQueueStates(workflows, priority) {
+		queue := queues[name]
+		if state.nextPending == nil || !canRunInQueue(queue, state.runningCount) {
+			continue
+		}
+		if needRunningWorkflow == nil || fairBefore(queue, state, needRunningQueue, needRunningState, priority) {
+			needRunningWorkflow, needRunningQueue, needRunningState = state.nextPending, queue, state
 		}
 	}
 	return needRunningWorkflow
 }
+
+// fairBefore priority 高的queue 优先，priority 相同时running/weight 小的queue 优先，都相同时比较各自下一个需要运行的workflow
+func fairBefore(a *v1alpha1.Queue, aState *queueState, b *v1alpha1.Queue, bState *queueState, priority workflowPriority) bool {
+	if queuePriority(a) != queuePriority(b) {
+		return queuePriority(a) > queuePriority(b)
+	}
+	// 交叉相乘比较 running/weight，避免浮点误差
+	aShare := int64(aState.runningCount) * int64(queueWeight(b))
+	bShare := int64(bState.runningCount) * int64(queueWeight(a))
+	if aShare != bShare {
+		return aShare < bShare
+	}
+	return priority.before(aState.nextPending, bState.nextPending)
+}
+
+// updateQueueStatus 更新Queue 的running、pending 数量
+func (r *queueReconciler) updateQueueStatus(ctx context.Context, queues []v1alpha1.Queue, states map[string]*queueState) error {
+	var errs []error
+	for i := range queues {
+		queue := &queues[i]
+		status := v1alpha1.QueueStatus{}
+		if state, ok := states[queue.Name]; ok {
+			status.Running = int32(state.runningCount)
+			status.Pending = int32(state.pendingCount)
+		}
+		if queue.Status == status {
+			continue
+		}
+		patchHelper, err := kube.NewHelper(queue, r.client)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		queue.Status = status
+		if err := patchHelper.Patch(ctx, queue); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return k8sutilerrors.NewAggregate(errs)
+}
+
+// workflowPriority 计算pending workflow 的有效优先级：spec.priority 加上等待的周期数
+type workflowPriority struct {
+	agingPeriod time.Duration
+	now         time.Time
+}
+
+func (p workflowPriority) effective(workflow *v1alpha1.Workflow) int32 {
+	priority := int64(workflow.Spec.Priority)
+	if p.agingPeriod > 0 && isPendingWorkflow(workflow) {
+		if waited := p.now.Sub(workflow.CreationTimestamp.Time); waited > 0 {
+			priority += int64(waited / p.agingPeriod)
+		}
+	}
+	if priority > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(priority)
+}
+
+// before 有效优先级高的workflow 优先，相同时更早创建的workflow 优先
+func (p workflowPriority) before(a, b *v1alpha1.Workflow) bool {
+	if aPriority, bPriority := p.effective(a), p.effective(b); aPriority != bPriority {
+		return aPriority > bPriority
+	}
+	return createdBefore(a, b)
+}
+
+// updateEffectivePriority 刷新pending workflow 的status.effectivePriority，便于kubectl get 查看
+func (r *queueReconciler) updateEffectivePriority(ctx context.Context, workflow *v1alpha1.Workflow, priority workflowPriority) error {
+	if !isPendingWorkflow(workflow) {
+		return nil
+	}
+	effectivePriority := priority.effective(workflow)
+	if workflow.Status.EffectivePriority == effectivePriority {
+		return nil
+	}
+	patchHelper, err := kube.NewHelper(workflow, r.client)
+	if err != nil {
+		return err
+	}
+	workflow.Status.EffectivePriority = effectivePriority
+	return patchHelper.Patch(ctx, workflow)
+}
+
+func findWorkflow(workflows []v1alpha1.Workflow, key types.NamespacedName) *v1alpha1.Workflow {
+	for i := range workflows {
+		if workflows[i].Namespace == key.Namespace && workflows[i].Name == key.Name {
+			return &workflows[i]
+		}
+	}
+	return nil
+}
+
+// createdBefore 按创建时间排序，创建时间相同时按名称排序，保证结果稳定
+func createdBefore(a, b *v1alpha1.Workflow) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
@@ -0,0 +1,290 @@
+package operators
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+func newQueueWorkflow(name, queue string, phase v1alpha1.WorkflowPhase, createdAgo time.Duration) v1alpha1.Workflow {
+	return v1alpha1.Workflow{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-createdAgo).Truncate(time.Second)),
+		},
+		Spec:   v1alpha1.WorkflowSpec{Queue: queue},
+		Status: v1alpha1.WorkflowStatus{Phase: phase},
+	}
+}
+
+func newQueue(name string, spec v1alpha1.QueueSpec) *v1alpha1.Queue {
+	return &v1alpha1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       spec,
+	}
+}
+
+func TestFindNeedRunningWorkflow(t *testing.T) {
+	cases := []struct {
+		name      string
+		workflows []v1alpha1.Workflow
+		queues    map[string]*v1alpha1.Queue
+		expect    string
+	}{
+		{
+			name:   "no workflow",
+			expect: "",
+		},
+		{
+			name: "no pending workflow",
+			workflows: []v1alpha1.Workflow{
+				newQueueWorkflow("a1", "a", v1alpha1.WorkflowRunning, time.Hour),
+				newQueueWorkflow("a2", "a", v1alpha1.WorkflowSuccess, time.Hour),
+			},
+			expect: "",
+		},
+		{
+			name: "oldest pending workflow in the queue",
+			workflows: []v1alpha1.Workflow{
+				newQueueWorkflow("a1", "a", v1alpha1.WorkflowPending, time.Minute),
+				newQueueWorkflow("a2", "a", v1alpha1.WorkflowPending, time.Hour),
+				newQueueWorkflow("a3", "a", v1alpha1.WorkflowPending, 2*time.Minute),
+			},
+			expect: "a2",
+		},
+		{
+			name: "queue with fewest running workflows",
+			workflows: []v1alpha1.Workflow{
+				newQueueWorkflow("a1", "a", v1alpha1.WorkflowRunning, time.Hour),
+				newQueueWorkflow("a2", "a", v1alpha1.WorkflowRollingBack, time.Hour),
+				newQueueWorkflow("a3", "a", v1alpha1.WorkflowPending, time.Hour),
+				newQueueWorkflow("b1", "b", v1alpha1.WorkflowRunning, time.Hour),
+				newQueueWorkflow("b2", "b", v1alpha1.WorkflowPending, time.Minute),
+			},
+			expect: "b2",
+		},
+		{
+			name: "finished workflows are not counted as running",
+			workflows: []v1alpha1.Workflow{
+				newQueueWorkflow("a1", "a", v1alpha1.WorkflowSuccess, time.Hour),
+				newQueueWorkflow("a2", "a", v1alpha1.WorkflowRollBacked, time.Hour),
+				newQueueWorkflow("a3", "a", v1alpha1.WorkflowPending, time.Minute),
+				newQueueWorkflow("b1", "b", v1alpha1.WorkflowRunning, time.Hour),
+				newQueueWorkflow("b2", "b", v1alpha1.WorkflowPending, time.Hour),
+			},
+			expect: "a3",
+		},
+		{
+			name: "same running count, older pending workflow first",
+			workflows: []v1alpha1.Workflow{
+				newQueueWorkflow("a1", "a", v1alpha1.WorkflowPending, time.Minute),
+				newQueueWorkflow("b1", "b", v1alpha1.WorkflowPending, time.Hour),
+				newQueueWorkflow("c1", "c", "", 2*time.Hour),
+			},
+			expect: "c1",
+		},
+		{
+			name: "paused queue is skipped",
+			workflows: []v1alpha1.Workflow{
+				newQueueWorkflow("a1", "a", v1alpha1.WorkflowPending, time.Hour),
+				newQueueWorkflow("b1", "b", v1alpha1.WorkflowRunning, time.Hour),
+				newQueueWorkflow("b2", "b", v1alpha1.WorkflowPending, time.Minute),
+			},
+			queues: map[string]*v1alpha1.Queue{
+				"a": newQueue("a", v1alpha1.QueueSpec{Paused: true}),
+			},
+			expect: "b2",
+		},
+		{
+			name: "queue exceeded maxRunning is skipped",
+			workflows: []v1alpha1.Workflow{
+				newQueueWorkflow("a1", "a", v1alpha1.WorkflowRunning, time.Hour),
+				newQueueWorkflow("a2", "a", v1alpha1.WorkflowPending, time.Hour),
+				newQueueWorkflow("b1", "b", v1alpha1.WorkflowRunning, time.Hour),
+				newQueueWorkflow("b2", "b", v1alpha1.WorkflowRunning, time.Hour),
+				newQueueWorkflow("b3", "b", v1alpha1.WorkflowPending, time.Minute),
+			},
+			queues: map[string]*v1alpha1.Queue{
+				"a": newQueue("a", v1alpha1.QueueSpec{MaxRunning: 1}),
+				"b": newQueue("b", v1alpha1.QueueSpec{MaxRunning: 3}),
+			},
+			expect: "b3",
+		},
+		{
+			name: "all queues exceeded maxRunning",
+			workflows: []v1alpha1.Workflow{
+				newQueueWorkflow("a1", "a", v1alpha1.WorkflowRunning, time.Hour),
+				newQueueWorkflow("a2", "a", v1alpha1.WorkflowPending, time.Hour),
+			},
+			queues: map[string]*v1alpha1.Queue{
+				"a": newQueue("a", v1alpha1.QueueSpec{MaxRunning: 1}),
+			},
+			expect: "",
+		},
+		{
+			name: "queue with higher priority first",
+			workflows: []v1alpha1.Workflow{
+				newQueueWorkflow("a1", "a", v1alpha1.WorkflowPending, time.Hour),
+				newQueueWorkflow("b1", "b", v1alpha1.WorkflowRunning, time.Hour),
+				newQueueWorkflow("b2", "b", v1alpha1.WorkflowPending, time.Minute),
+			},
+			queues: map[string]*v1alpha1.Queue{
+				"b": newQueue("b", v1alpha1.QueueSpec{Priority: 10}),
+			},
+			expect: "b2",
+		},
+		{
+			name: "queue with lower running/weight first",
+			workflows: []v1alpha1.Workflow{
+				newQueueWorkflow("a1", "a", v1alpha1.WorkflowRunning, time.Hour),
+				newQueueWorkflow("a2", "a", v1alpha1.WorkflowPending, time.Hour),
+				newQueueWorkflow("b1", "b", v1alpha1.WorkflowRunning, time.Hour),
+				newQueueWorkflow("b2", "b", v1alpha1.WorkflowRunning, time.Hour),
+				newQueueWorkflow("b3", "b", v1alpha1.WorkflowPending, time.Minute),
+			},
+			queues: map[string]*v1alpha1.Queue{
+				"b": newQueue("b", v1alpha1.QueueSpec{Weight: 4}),
+			},
+			expect: "b3",
+		},
+	}
+	for _, c := range cases {
+		got := findNeedRunningWorkflow(c.workflows, c.queues, workflowPriority{now: time.Now()})
+		gotName := ""
+		if got != nil {
+			gotName = got.Name
+		}
+		if gotName != c.expect {
+			t.Errorf("%s: expect %q, got %q", c.name, c.expect, gotName)
+		}
+	}
+}
+
+func newPriorityWorkflow(name, queue string, priority int32, createdAgo time.Duration) v1alpha1.Workflow {
+	workflow := newQueueWorkflow(name, queue, v1alpha1.WorkflowPending, createdAgo)
+	workflow.Spec.Priority = priority
+	return workflow
+}
+
+func TestFindNeedRunningWorkflowWithPriority(t *testing.T) {
+	cases := []struct {
+		name        string
+		workflows   []v1alpha1.Workflow
+		agingPeriod time.Duration
+		expect      string
+	}{
+		{
+			name: "higher priority first in the queue",
+			workflows: []v1alpha1.Workflow{
+				newPriorityWorkflow("a1", "a", 0, time.Hour),
+				newPriorityWorkflow("a2", "a", 10, time.Minute),
+				newPriorityWorkflow("a3", "a", 5, 2*time.Minute),
+			},
+			expect: "a2",
+		},
+		{
+			name: "same priority, older first",
+			workflows: []v1alpha1.Workflow{
+				newPriorityWorkflow("a1", "a", 5, time.Minute),
+				newPriorityWorkflow("a2", "a", 5, time.Hour),
+			},
+			expect: "a2",
+		},
+		{
+			name: "low priority workflow is aged",
+			workflows: []v1alpha1.Workflow{
+				newPriorityWorkflow("a1", "a", 0, time.Hour),
+				newPriorityWorkflow("a2", "a", 10, time.Minute),
+			},
+			agingPeriod: 5 * time.Minute,
+			expect:      "a1",
+		},
+		{
+			name: "aging is not enough",
+			workflows: []v1alpha1.Workflow{
+				newPriorityWorkflow("a1", "a", 0, 20*time.Minute),
+				newPriorityWorkflow("a2", "a", 10, time.Minute),
+			},
+			agingPeriod: 5 * time.Minute,
+			expect:      "a2",
+		},
+		{
+			name: "same running count, higher priority workflow first across queues",
+			workflows: []v1alpha1.Workflow{
+				newPriorityWorkflow("a1", "a", 0, time.Hour),
+				newPriorityWorkflow("b1", "b", 1, time.Minute),
+			},
+			expect: "b1",
+		},
+	}
+	for _, c := range cases {
+		priority := workflowPriority{agingPeriod: c.agingPeriod, now: time.Now()}
+		got := findNeedRunningWorkflow(c.workflows, nil, priority)
+		gotName := ""
+		if got != nil {
+			gotName = got.Name
+		}
+		if gotName != c.expect {
+			t.Errorf("%s: expect %q, got %q", c.name, c.expect, gotName)
+		}
+	}
+}
+
+func TestEffectivePriority(t *testing.T) {
+	now := time.Now()
+	priority := workflowPriority{agingPeriod: time.Minute, now: now}
+	pending := newPriorityWorkflow("a1", "a", 3, 0)
+	pending.CreationTimestamp = metav1.NewTime(now.Add(-150 * time.Second))
+	if got := priority.effective(&pending); got != 5 {
+		t.Errorf("pending workflow: expect 5, got %d", got)
+	}
+	running := pending.DeepCopy()
+	running.Status.Phase = v1alpha1.WorkflowRunning
+	if got := priority.effective(running); got != 3 {
+		t.Errorf("running workflow: expect 3, got %d", got)
+	}
+	if got := (workflowPriority{now: now}).effective(&pending); got != 3 {
+		t.Errorf("no aging: expect 3, got %d", got)
+	}
+	pending.Spec.Priority = math.MaxInt32
+	if got := priority.effective(&pending); got != math.MaxInt32 {
+		t.Errorf("overflow: expect %d, got %d", math.MaxInt32, got)
+	}
+}
+
+func TestCountRunningWorkflow(t *testing.T) {
+	workflows := []v1alpha1.Workflow{
+		newQueueWorkflow("a1", "a", v1alpha1.WorkflowRunning, time.Hour),
+		newQueueWorkflow("a2", "a", v1alpha1.WorkflowRollingBack, time.Hour),
+		newQueueWorkflow("a3", "a", v1alpha1.WorkflowPending, time.Hour),
+		newQueueWorkflow("a4", "a", v1alpha1.WorkflowSuccess, time.Hour),
+		newQueueWorkflow("a5", "a", v1alpha1.WorkflowFailed, time.Hour),
+	}
+	if count := countRunningWorkflow(workflows); count != 2 {
+		t.Fatalf("expect 2 running workflows, got %d", count)
+	}
+}
+
+func TestCanRunInQueue(t *testing.T) {
+	cases := []struct {
+		name         string
+		queue        *v1alpha1.Queue
+		runningCount int
+		expect       bool
+	}{
+		{name: "no queue object", runningCount: 100, expect: true},
+		{name: "paused", queue: newQueue("a", v1alpha1.QueueSpec{Paused: true}), expect: false},
+		{name: "no maxRunning", queue: newQueue("a", v1alpha1.QueueSpec{}), runningCount: 100, expect: true},
+		{name: "under maxRunning", queue: newQueue("a", v1alpha1.QueueSpec{MaxRunning: 2}), runningCount: 1, expect: true},
+		{name: "reach maxRunning", queue: newQueue("a", v1alpha1.QueueSpec{MaxRunning: 2}), runningCount: 2, expect: false},
+	}
+	for _, c := range cases {
+		if got := canRunInQueue(c.queue, c.runningCount); got != c.expect {
+			t.Errorf("%s: expect %v, got %v", c.name, c.expect, got)
+		}
+	}
+}
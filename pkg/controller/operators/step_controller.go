@@ -34,6 +34,7 @@ import (
 	"github.com/qiankunli/workflow/pkg/utils"
 	"github.com/qiankunli/workflow/pkg/utils/kube"
 	"github.com/qiankunli/workflow/pkg/utils/mutex"
+	"github.com/qiankunli/workflow/pkg/utils/redact"
 
 	// 引入example step
 	_ "github.com/qiankunli/workflow/pkg/controller/step/example"
@@ -48,6 +49,10 @@ type stepReconciler struct {
 	log           logr.Logger
 	recorder      record.EventRecorder
 	StepMutex     mutex.GroupMutex
+	// 单次 Run/Rollback/Sync 的超时时间
+	timeout time.Duration
+	// 抹去日志中的secret 值，只在解析了parametersFrom 的reconcile 中设置
+	redactor *redact.Redactor
 }
 
 // RegisterStepReconciler ...
@@ -59,6 +64,7 @@ func RegisterStepReconciler(mgr ctrl.Manager, controllerCtx *manager.ControllerC
 		log:           ctrl.LoggerFrom(context.Background()).WithName(name),
 		recorder:      mgr.GetEventRecorderFor(name),
 		StepMutex:     controllerCtx.StepMutex,
+		timeout:       utils.FirstNotZeroDuration(stepConfig.Timeout, controllerCtx.Config.ControllerConfig.StepTimeout).Duration,
 	}
 
 	// 只执行特性类型的step
@@ -137,6 +143,12 @@ func (r *stepReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res c
 			reterr = k8sutilerrors.NewAggregate([]error{reterr, err})
 		}
 	}()
+	// 用进入Running 时解析出的parameters 和parametersFrom 运行，patch 之前恢复spec
+	if len(step.Status.ResolvedParameters) > 0 || len(step.Spec.ParametersFrom) > 0 {
+		parameters := step.Spec.Parameters
+		defer func() { step.Spec.Parameters = parameters }()
+		applyResolvedParameters(step)
+	}
 
 	// workflow own step，从日志上看，workflow 被完全删除时，step  DeletionTimestamp 开始不为空
 	if !step.DeletionTimestamp.IsZero() {
@@ -167,42 +179,82 @@ func (r *stepReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res c
 		return ctrl.Result{}, err
 	}
 	log.V(4).Info("step start reconcile", "workflow.Phase", workflow.Status.Phase, "workflow.DeletionTimestamp", workflow.DeletionTimestamp)
-
+	// 引用模板的workflow 按快照中的parameters 运行，这里不会更新workflow
+	applyStoredSpec(workflow)
 	if step.Status.Phase == v1alpha1.StepRunning {
-		log.V(4).Info("try run step run", "LatestRunRetryAt", step.Status.LatestRunRetryAt)
-		needWaitDuration := time.Duration(step.Spec.RetryPolicy.RunRetryPeriodSeconds) * time.Second
-		if !step.Status.LatestRunRetryAt.IsZero() {
-			nextRunAt := step.Status.LatestRunRetryAt.Time.Add(needWaitDuration)
-			needWaitDuration = time.Until(nextRunAt)
+		if step.Status.StartedAt.IsZero() {
+			step.Status.StartedAt = metav1.Now()
+		}
+		// 超时则进入RollingBack，由下面的逻辑开始回滚
+		r.reconcileTimeout(step)
+	}
+	// parametersFrom 只在内存中解析，secret 的值会从status、日志和事件中抹去
+	if needParametersFrom(step) {
+		secrets, err := resolveParametersFrom(ctx, r.client, step)
+		if err != nil {
+			// 和Run/Rollback 失败一样计入重试次数，超过重试次数后回滚或失败
+			return r.reconcileParametersFromError(workflow, step, err), nil
+		}
+		redactor := redact.New(secrets...)
+		defer redactStepStatus(step, redactor)
+		r = r.withRedactor(redactor)
+	}
+	if step.Status.Phase == v1alpha1.StepRunning && !step.Status.SubmittedAt.IsZero() {
+		// 异步step 已提交任务，轮询任务状态
+		log.V(4).Info("try poll step", "LatestPollAt", step.Status.LatestPollAt)
+		pollPeriod := getPollPeriod(step)
+		if !step.Status.LatestPollAt.IsZero() {
+			needWaitDuration := time.Until(step.Status.LatestPollAt.Time.Add(pollPeriod))
 			if needWaitDuration > 0 {
-				// 没到执行时间
-				return ctrl.Result{RequeueAfter: needWaitDuration}, nil
+				// 没到轮询时间
+				return ctrl.Result{RequeueAfter: capByStepTimeout(step, needWaitDuration)}, nil
 			}
 		}
+		r.reconcilePoll(ctx, workflow, step)
+		if step.Status.Phase == v1alpha1.StepRunning {
+			// 任务失败需要重新提交，等待重试间隔
+			if step.Status.SubmittedAt.IsZero() {
+				return ctrl.Result{RequeueAfter: capByStepTimeout(step, requeueAfterRetry(runRetryWait(step)))}, nil
+			}
+			return ctrl.Result{RequeueAfter: capByStepTimeout(step, pollPeriod)}, nil
+		}
+		// 成功则进入Success，还需sync，所以过一会儿入队
+		return ctrl.Result{RequeueAfter: constants.DefaultRequeueDuration}, nil
+	}
+	if step.Status.Phase == v1alpha1.StepRunning {
+		log.V(4).Info("try run step run", "LatestRunRetryAt", step.Status.LatestRunRetryAt, "NextRunRetryAt", step.Status.NextRunRetryAt)
+		if needWaitDuration := runRetryWait(step); needWaitDuration > 0 {
+			// 没到执行时间
+			return ctrl.Result{RequeueAfter: capByStepTimeout(step, needWaitDuration)}, nil
+		}
+		// workflow 暂停时不开始新的Run/重试，恢复后从这里继续
+		if workflow.Spec.Suspend {
+			log.V(4).Info("workflow is suspended, hold step run")
+			return ctrl.Result{RequeueAfter: capByStepTimeout(step, constants.DefaultRequeueDuration)}, nil
+		}
 		// 到了执行时间
 		r.reconcileRun(ctx, workflow, step)
 		// 没成功下次继续
 		if step.Status.Phase == v1alpha1.StepRunning {
-			return ctrl.Result{RequeueAfter: needWaitDuration}, nil
+			// 异步step 提交了任务，过一个轮询间隔再来看下
+			if !step.Status.SubmittedAt.IsZero() {
+				return ctrl.Result{RequeueAfter: capByStepTimeout(step, getPollPeriod(step))}, nil
+			}
+			return ctrl.Result{RequeueAfter: capByStepTimeout(step, requeueAfterRetry(runRetryWait(step)))}, nil
 		}
 		// 成功则进入Success，还需sync，所以过一会儿入队
 		return ctrl.Result{RequeueAfter: constants.DefaultRequeueDuration}, nil
 	}
 	if step.Status.Phase == v1alpha1.StepRollingBack {
-		log.V(4).Info("try run step rollback", "LatestRollbackRetryAt", step.Status.LatestRollbackRetryAt)
-		needWaitDuration := time.Duration(step.Spec.RetryPolicy.RollbackRetryPeriodSeconds) * time.Second
-		if !step.Status.LatestRollbackRetryAt.IsZero() {
-			nextRollbackAt := step.Status.LatestRollbackRetryAt.Time.Add(needWaitDuration)
-			needWaitDuration = time.Until(nextRollbackAt)
-			if needWaitDuration > 0 {
-				// 没到执行时间
-				return ctrl.Result{RequeueAfter: needWaitDuration}, nil
-			}
+		log.V(4).Info("try run step rollback", "LatestRollbackRetryAt", step.Status.LatestRollbackRetryAt, "NextRollbackRetryAt", step.Status.NextRollbackRetryAt)
+		if needWaitDuration := rollbackRetryWait(step); needWaitDuration > 0 {
+			// 没到执行时间
+			return ctrl.Result{RequeueAfter: needWaitDuration}, nil
 		}
 		r.reconcileRollback(ctx, workflow, step)
 		// 没成功下次继续
 		if step.Status.Phase == v1alpha1.StepRollingBack {
-			return ctrl.Result{RequeueAfter: needWaitDuration}, nil
+			return ctrl.Result{RequeueAfter: requeueAfterRetry(rollbackRetryWait(step))}, nil
 		}
 		// 成功则进入RollBacked
 		return ctrl.Result{}, nil
@@ -227,21 +279,103 @@ func (r *stepReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res c
 	return ctrl.Result{}, nil
 }
 
+var getPollPeriod = func(step *v1alpha1.Step) time.Duration {
+	if step.Spec.PollPeriodSeconds <= 0 {
+		return constants.DefaultRequeueDuration
+	}
+	return time.Duration(step.Spec.PollPeriodSeconds) * time.Second
+}
+
+// stepTimeoutLeft 距离step 超时的剩余时间，没有设置timeoutSeconds 时返回false
+var stepTimeoutLeft = func(step *v1alpha1.Step) (time.Duration, bool) {
+	if step.Spec.TimeoutSeconds <= 0 || step.Status.StartedAt.IsZero() {
+		return 0, false
+	}
+	return time.Until(step.Status.StartedAt.Add(time.Duration(step.Spec.TimeoutSeconds) * time.Second)), true
+}
+
+// capByStepTimeout 等待时间不超过step 超时的时间，以便及时发现超时
+var capByStepTimeout = func(step *v1alpha1.Step, d time.Duration) time.Duration {
+	if left, ok := stepTimeoutLeft(step); ok && left < d {
+		return left + time.Second
+	}
+	return d
+}
+
+// reconcileTimeout 超时的step 进入RollingBack
+func (r *stepReconciler) reconcileTimeout(step *v1alpha1.Step) {
+	left, ok := stepTimeoutLeft(step)
+	if !ok || left > 0 {
+		return
+	}
+	currentPhase := step.Status.Phase
+	step.Status.Phase = v1alpha1.StepRollingBack
+	step.Status.Reason = v1alpha1.TimeoutReason
+	step.Status.RunError = fmt.Sprintf("step timeout, running more than %d seconds", step.Spec.TimeoutSeconds)
+	r.recorder.Eventf(step, corev1.EventTypeNormal, v1alpha1.TimeoutReason, "'%s' => '%s',%s", currentPhase, step.Status.Phase, step.Status.RunError)
+}
+
+// applyResolvedParameters 用status 中解析出的parameters 替换spec.parameters 中对应的模板
+func applyResolvedParameters(step *v1alpha1.Step) {
+	parameters := make(map[string]string, len(step.Spec.Parameters))
+	for k, v := range step.Spec.Parameters {
+		parameters[k] = v
+	}
+	for k, v := range step.Status.ResolvedParameters {
+		parameters[k] = v
+	}
+	step.Spec.Parameters = parameters
+}
+
 var seeAsRollBackedStep = func(step *v1alpha1.Step) bool {
 	// 都回滚完成了，才开始真正删除
 	if step.Status.Phase == v1alpha1.StepRollBacked {
 		return true
 	}
+	// 被跳过的step 没有运行过，不需要回滚
+	if step.Status.Phase == v1alpha1.StepSkipped {
+		return true
+	}
 	if step.Status.Phase == v1alpha1.StepFailed && step.Spec.RollbackPolicy == v1alpha1.Always {
 		return true
 	}
 	return false
 }
 
-func (r *stepReconciler) reconcileSync(_ context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) {
+// stepContext 传给step 的ctx，manager 退出、超时或者cancelOnDeleted 时workflow 被删除都会cancel
+func (r *stepReconciler) stepContext(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step, cancelOnDeleted bool) (context.Context, context.CancelFunc) {
+	timeout := r.timeout
+	// Running 的step 不能超过step 自身的超时时间
+	if left, ok := stepTimeoutLeft(step); ok && step.Status.Phase == v1alpha1.StepRunning && (timeout <= 0 || left < timeout) {
+		timeout = left
+	}
+	var stepCtx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		stepCtx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		stepCtx, cancel = context.WithCancel(ctx)
+	}
+	if !cancelOnDeleted {
+		return stepCtx, cancel
+	}
+	// workflow 已经在删除了，没必要再继续执行
+	if !workflow.DeletionTimestamp.IsZero() {
+		cancel()
+		return stepCtx, cancel
+	}
+	group := workflow.Namespace + workflow.Name
+	r.controllerCtx.StepCanceler.Add(group, step.Name, cancel)
+	return stepCtx, func() {
+		r.controllerCtx.StepCanceler.Remove(group, step.Name)
+		cancel()
+	}
+}
+
+func (r *stepReconciler) reconcileSync(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) {
 	log := r.log.WithValues("name", step.Name)
 	currentPhase := step.Status.Phase
-	s, err := stepinterface.NewStep(r.controllerCtx.Config, workflow, step)
+	s, err := stepinterface.NewStepV2(r.controllerCtx.Config, workflow, step)
 	if err != nil {
 		log.Error(err, "instantiate step error")
 		step.Status.SyncError = err.Error()
@@ -249,9 +383,11 @@ func (r *stepReconciler) reconcileSync(_ context.Context, workflow *v1alpha1.Wor
 		return
 	}
 	step.Status.LatestSyncAt = metav1.Now()
-	stepErr := s.Sync(workflow, step)
+	syncCtx, cancel := r.stepContext(ctx, workflow, step, true)
+	defer cancel()
+	stepErr := s.Sync(syncCtx, workflow, step)
 	if stepErr != nil {
-		log.Error(stepErr, "step sync error")
+		log.Error(r.redactError(stepErr), "step sync error")
 		step.Status.SyncError = stepErr.Error()
 		if !stepErr.Retryable() {
 			// 发现不可重试的错误，立即触发回滚
@@ -0,0 +1,86 @@
+package operators
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+const defaultBackoffFactor = 2
+
+// retryPeriod 按退避策略计算下次重试前的等待时间，retryCount 为已经重试的次数
+var retryPeriod = func(policy v1alpha1.RetryPolicy, basePeriodSeconds int32, retryCount int32) time.Duration {
+	factor := float64(policy.BackoffFactor)
+	if factor <= 0 {
+		factor = defaultBackoffFactor
+	}
+	n := float64(0)
+	if retryCount > 1 {
+		n = float64(retryCount - 1)
+	}
+	period := float64(basePeriodSeconds)
+	switch policy.Backoff {
+	case v1alpha1.ExponentialBackoff:
+		period = period * math.Pow(factor, n)
+	case v1alpha1.LinearBackoff:
+		period = period * (1 + factor*n)
+	}
+	if policy.MaxRetryPeriodSeconds > 0 && period > float64(policy.MaxRetryPeriodSeconds) {
+		period = float64(policy.MaxRetryPeriodSeconds)
+	}
+	if policy.JitterPercent > 0 {
+		period += period * float64(policy.JitterPercent) / 100 * rand.Float64()
+	}
+	// 防止指数增长溢出
+	if period >= float64(math.MaxInt64)/float64(time.Second) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(period * float64(time.Second))
+}
+
+// nextRunRetryAt 记录按退避策略计算出的下次运行时间
+func nextRunRetryAt(step *v1alpha1.Step) {
+	period := retryPeriod(step.Spec.RetryPolicy, step.Spec.RetryPolicy.RunRetryPeriodSeconds, step.Status.RunRetryCount)
+	step.Status.NextRunRetryAt = metav1.NewTime(step.Status.LatestRunRetryAt.Add(period))
+}
+
+// nextRollbackRetryAt 记录按退避策略计算出的下次回滚时间
+func nextRollbackRetryAt(step *v1alpha1.Step) {
+	period := retryPeriod(step.Spec.RetryPolicy, step.Spec.RetryPolicy.RollbackRetryPeriodSeconds, step.Status.RollbackRetryCount)
+	step.Status.NextRollbackRetryAt = metav1.NewTime(step.Status.LatestRollbackRetryAt.Add(period))
+}
+
+// runRetryWait 距离下次运行还要等待的时间
+var runRetryWait = func(step *v1alpha1.Step) time.Duration {
+	if !step.Status.NextRunRetryAt.IsZero() {
+		return time.Until(step.Status.NextRunRetryAt.Time)
+	}
+	// 兼容没有记录 NextRunRetryAt 的step
+	if step.Status.LatestRunRetryAt.IsZero() {
+		return 0
+	}
+	return time.Until(step.Status.LatestRunRetryAt.Add(time.Duration(step.Spec.RetryPolicy.RunRetryPeriodSeconds) * time.Second))
+}
+
+// rollbackRetryWait 距离下次回滚还要等待的时间
+var rollbackRetryWait = func(step *v1alpha1.Step) time.Duration {
+	if !step.Status.NextRollbackRetryAt.IsZero() {
+		return time.Until(step.Status.NextRollbackRetryAt.Time)
+	}
+	if step.Status.LatestRollbackRetryAt.IsZero() {
+		return 0
+	}
+	return time.Until(step.Status.LatestRollbackRetryAt.Add(time.Duration(step.Spec.RetryPolicy.RollbackRetryPeriodSeconds) * time.Second))
+}
+
+// requeueAfterRetry 重试等待时间至少1s，RequeueAfter 为0 时不会再次入队
+var requeueAfterRetry = func(d time.Duration) time.Duration {
+	if d < time.Second {
+		return time.Second
+	}
+	return d
+}
@@ -0,0 +1,41 @@
+package operators
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+func TestRetryPeriod(t *testing.T) {
+	cases := []struct {
+		name       string
+		policy     v1alpha1.RetryPolicy
+		retryCount int32
+		expect     time.Duration
+	}{
+		{"fixed", v1alpha1.RetryPolicy{Backoff: v1alpha1.FixedBackoff}, 3, 10 * time.Second},
+		{"empty backoff as fixed", v1alpha1.RetryPolicy{}, 3, 10 * time.Second},
+		{"exponential first retry", v1alpha1.RetryPolicy{Backoff: v1alpha1.ExponentialBackoff, BackoffFactor: 2}, 1, 10 * time.Second},
+		{"exponential", v1alpha1.RetryPolicy{Backoff: v1alpha1.ExponentialBackoff, BackoffFactor: 3}, 3, 90 * time.Second},
+		{"exponential default factor", v1alpha1.RetryPolicy{Backoff: v1alpha1.ExponentialBackoff}, 4, 80 * time.Second},
+		{"exponential max period", v1alpha1.RetryPolicy{Backoff: v1alpha1.ExponentialBackoff, BackoffFactor: 2, MaxRetryPeriodSeconds: 30}, 10, 30 * time.Second},
+		{"linear", v1alpha1.RetryPolicy{Backoff: v1alpha1.LinearBackoff, BackoffFactor: 1}, 3, 30 * time.Second},
+		{"exponential overflow", v1alpha1.RetryPolicy{Backoff: v1alpha1.ExponentialBackoff, BackoffFactor: 10}, 100, time.Duration(1<<63 - 1)},
+	}
+	for _, c := range cases {
+		if got := retryPeriod(c.policy, 10, c.retryCount); got != c.expect {
+			t.Errorf("%s: expect %v, got %v", c.name, c.expect, got)
+		}
+	}
+}
+
+func TestRetryPeriodJitter(t *testing.T) {
+	policy := v1alpha1.RetryPolicy{Backoff: v1alpha1.FixedBackoff, JitterPercent: 50}
+	for i := 0; i < 100; i++ {
+		got := retryPeriod(policy, 10, 1)
+		if got < 10*time.Second || got >= 15*time.Second {
+			t.Fatalf("expect period in [10s, 15s), got %v", got)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package operators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/constants"
+	"github.com/qiankunli/workflow/pkg/utils/kube"
+	"github.com/qiankunli/workflow/pkg/utils/redact"
+)
+
+// needParametersFrom 只有运行、回滚、sync 时才需要解析parametersFrom
+func needParametersFrom(step *v1alpha1.Step) bool {
+	if len(step.Spec.ParametersFrom) == 0 {
+		return false
+	}
+	switch step.Status.Phase {
+	case v1alpha1.StepRunning, v1alpha1.StepRollingBack:
+		return true
+	case v1alpha1.StepSuccess:
+		return step.Spec.SyncPeriodSeconds > 0
+	}
+	return false
+}
+
+// resolveParametersFrom 读取parametersFrom 合入step.Spec.Parameters，返回secret 的值用于脱敏。
+// 调用方要保证step.Spec.Parameters 是副本，patch 之前恢复
+func resolveParametersFrom(ctx context.Context, c client.Client, step *v1alpha1.Step) ([]string, error) {
+	secrets := make([]string, 0)
+	for _, from := range step.Spec.ParametersFrom {
+		var value []byte
+		var err error
+		switch {
+		case from.SecretKeyRef != nil:
+			value, err = kube.GetSecretV2(ctx, c, step.Namespace, from.SecretKeyRef)
+			if err == nil {
+				secrets = append(secrets, string(value))
+			}
+		case from.ConfigMapKeyRef != nil:
+			value, err = kube.GetConfigMapV2(ctx, c, step.Namespace, from.ConfigMapKeyRef)
+		default:
+			err = fmt.Errorf("no source is set")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %v", from.Name, err)
+		}
+		step.Spec.Parameters[from.Name] = string(value)
+	}
+	return secrets, nil
+}
+
+// redactStepStatus 抹去step 错误信息、attributes 和outputs 中的secret 值，workflow 汇总的结果和回调也就不会包含secret
+func redactStepStatus(step *v1alpha1.Step, redactor *redact.Redactor) {
+	step.Status.RunError = redactor.Redact(step.Status.RunError)
+	step.Status.RollbackError = redactor.Redact(step.Status.RollbackError)
+	step.Status.SyncError = redactor.Redact(step.Status.SyncError)
+	redactor.RedactMap(step.Status.Attributes)
+	redactor.RedactMap(step.Status.Resource.Attributes)
+	if step.Status.Outputs != nil {
+		step.Status.Outputs.Raw = redactor.RedactJSON(step.Status.Outputs.Raw)
+	}
+}
+
+// withRedactor 返回发出的事件和日志会抹去secret 值的reconciler 副本，只在本次reconcile 中使用
+func (r *stepReconciler) withRedactor(redactor *redact.Redactor) *stepReconciler {
+	copied := *r
+	copied.recorder = redact.NewEventRecorder(r.recorder, redactor)
+	copied.redactor = redactor
+	return &copied
+}
+
+// redactError 抹去错误信息中的secret 值，用于打印日志
+func (r *stepReconciler) redactError(err error) error {
+	if r.redactor == nil || err == nil {
+		return err
+	}
+	return errors.New(r.redactor.Redact(err.Error()))
+}
+
+// reconcileParametersFromError parametersFrom 解析失败时按当前phase 记录错误并计入重试次数，返回下次reconcile 的时间
+func (r *stepReconciler) reconcileParametersFromError(workflow *v1alpha1.Workflow, step *v1alpha1.Step, err error) ctrl.Result {
+	log := r.log.WithValues("name", step.Name)
+	log.Error(err, "failed to resolve parametersFrom")
+	currentPhase := step.Status.Phase
+	message := fmt.Sprintf("resolve parametersFrom error: %v", err)
+	switch currentPhase {
+	case v1alpha1.StepRunning:
+		if needWaitDuration := runRetryWait(step); needWaitDuration > 0 {
+			return ctrl.Result{RequeueAfter: capByStepTimeout(step, needWaitDuration)}
+		}
+		// workflow 暂停时不开始新的重试
+		if workflow.Spec.Suspend {
+			return ctrl.Result{RequeueAfter: capByStepTimeout(step, constants.DefaultRequeueDuration)}
+		}
+		if step.Status.RunRetryCount >= step.Spec.RetryPolicy.RunRetryLimit {
+			// 超过重试次数，则放弃，开始回滚
+			step.Status.Phase = v1alpha1.StepRollingBack
+			r.recorder.Eventf(step, corev1.EventTypeNormal, v1alpha1.PhaseChangeReason, "'%s' => '%s',over RunRetryLimit",
+				currentPhase, step.Status.Phase)
+			return ctrl.Result{RequeueAfter: constants.DefaultRequeueDuration}
+		}
+		step.Status.RunRetryCount++
+		step.Status.LatestRunRetryAt = metav1.Now()
+		nextRunRetryAt(step)
+		step.Status.RunError = message
+		r.recorder.Eventf(step, corev1.EventTypeWarning, v1alpha1.FailedOrErrorReason, "runRetryCount=%d %s", step.Status.RunRetryCount, message)
+		return ctrl.Result{RequeueAfter: capByStepTimeout(step, requeueAfterRetry(runRetryWait(step)))}
+	case v1alpha1.StepRollingBack:
+		if needWaitDuration := rollbackRetryWait(step); needWaitDuration > 0 {
+			return ctrl.Result{RequeueAfter: needWaitDuration}
+		}
+		// 如果 RollbackRetryLimit <=0 则认为无限制重试
+		if step.Spec.RetryPolicy.RollbackRetryLimit > 0 && step.Status.RollbackRetryCount >= step.Spec.RetryPolicy.RollbackRetryLimit {
+			step.Status.Phase = v1alpha1.StepFailed
+			r.recorder.Eventf(step, corev1.EventTypeNormal, v1alpha1.FailedOrErrorReason, "'%s' => '%s',over RollbackRetryLimit",
+				currentPhase, v1alpha1.StepFailed)
+			return ctrl.Result{}
+		}
+		step.Status.RollbackRetryCount++
+		step.Status.LatestRollbackRetryAt = metav1.Now()
+		nextRollbackRetryAt(step)
+		step.Status.RollbackError = message
+		r.recorder.Eventf(step, corev1.EventTypeWarning, v1alpha1.FailedOrErrorReason, "rollbackRetryCount=%d %s", step.Status.RollbackRetryCount, message)
+		return ctrl.Result{RequeueAfter: requeueAfterRetry(rollbackRetryWait(step))}
+	}
+	// sync 失败不影响phase，下个sync 周期再试
+	step.Status.SyncError = message
+	step.Status.LatestSyncAt = metav1.Now()
+	r.recorder.Eventf(step, corev1.EventTypeWarning, v1alpha1.FailedOrErrorReason, message)
+	return ctrl.Result{RequeueAfter: time.Duration(step.Spec.SyncPeriodSeconds) * time.Second}
+}
@@ -0,0 +1,155 @@
+package operators
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/funcr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
+	"github.com/qiankunli/workflow/pkg/options"
+	"github.com/qiankunli/workflow/pkg/utils/redact"
+)
+
+// loginStep 运行失败，错误信息、attributes 和outputs 中带有password
+type loginStep struct{}
+
+func (s *loginStep) Run(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	dsn := fmt.Sprintf("%s:%s@%s", step.Spec.Parameters["user"], step.Spec.Parameters["password"], step.Spec.Parameters["endpoint"])
+	step.Status.Attributes = map[string]string{"dsn": dsn}
+	step.Status.Resource.Attributes["dsn"] = dsn
+	step.Status.Outputs = &runtime.RawExtension{Raw: []byte(fmt.Sprintf(`{"db":{"dsn":%q}}`, dsn))}
+	return stepinterface.NewStepError(fmt.Errorf("login %s@%s with %s failed",
+		step.Spec.Parameters["user"], step.Spec.Parameters["endpoint"], step.Spec.Parameters["password"]), false, false)
+}
+
+func (s *loginStep) Rollback(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	return nil
+}
+
+func (s *loginStep) Sync(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	return nil
+}
+
+func TestReconcileParametersFrom(t *testing.T) {
+	stepinterface.FactoryV2["login-test"] = func(cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (stepinterface.StepV2, error) {
+		return &loginStep{}, nil
+	}
+	defer delete(stepinterface.FactoryV2, "login-test")
+
+	workflow, step := newTestStepWorkflow("login-test")
+	step.Spec.Parameters = map[string]string{"user": "admin"}
+	step.Spec.ParametersFrom = []v1alpha1.ParameterFrom{
+		{Name: "password", SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"}},
+		{Name: "endpoint", ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "endpoint"}},
+	}
+	secret := &corev1.Secret{Data: map[string][]byte{"password": []byte("s3cr3t")}}
+	secret.Name = "db"
+	secret.Namespace = "default"
+	configmap := &corev1.ConfigMap{Data: map[string]string{"endpoint": "db.local"}}
+	configmap.Name = "db"
+	configmap.Namespace = "default"
+
+	r := newTestStepReconciler(workflow, secret, configmap, step)
+	recorder := r.recorder.(*record.FakeRecorder)
+	logs := make([]string, 0)
+	r.log = funcr.New(func(prefix, args string) { logs = append(logs, args) }, funcr.Options{})
+	_, actual := reconcileTestStep(t, r, step)
+	// configMap 的值不需要抹去
+	if expect := "login admin@db.local with " + redact.Mask + " failed"; actual.Status.RunError != expect {
+		t.Errorf("expect run error %q, got %q", expect, actual.Status.RunError)
+	}
+	if len(actual.Spec.Parameters) != 1 {
+		t.Errorf("expect parametersFrom not written to spec, got %v", actual.Spec.Parameters)
+	}
+	close(recorder.Events)
+	for event := range recorder.Events {
+		if strings.Contains(event, "s3cr3t") {
+			t.Errorf("expect secret redacted in event %s", event)
+		}
+	}
+	// attributes 和outputs 会汇总到workflow 并出现在回调中
+	dsn := "admin:" + redact.Mask + "@db.local"
+	if actual.Status.Attributes["dsn"] != dsn || actual.Status.Resource.Attributes["dsn"] != dsn {
+		t.Errorf("expect secret redacted in attributes, got %v %v", actual.Status.Attributes, actual.Status.Resource.Attributes)
+	}
+	if actual.Status.Outputs == nil || strings.Contains(string(actual.Status.Outputs.Raw), "s3cr3t") {
+		t.Errorf("expect secret redacted in outputs, got %v", actual.Status.Outputs)
+	}
+	for _, log := range logs {
+		if strings.Contains(log, "s3cr3t") {
+			t.Errorf("expect secret redacted in log %s", log)
+		}
+	}
+}
+
+func TestReconcileParametersFromError(t *testing.T) {
+	stepinterface.FactoryV2["login-test"] = func(cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (stepinterface.StepV2, error) {
+		return &loginStep{}, nil
+	}
+	defer delete(stepinterface.FactoryV2, "login-test")
+
+	workflow, step := newTestStepWorkflow("login-test")
+	step.Spec.RetryPolicy.RollbackRetryLimit = 1
+	step.Spec.Parameters = map[string]string{}
+	step.Spec.ParametersFrom = []v1alpha1.ParameterFrom{
+		{Name: "password", SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"}},
+	}
+	r := newTestStepReconciler(workflow, step)
+	// secret 不存在时计入重试次数，而不是返回错误一直重新入队
+	res, actual := reconcileTestStep(t, r, step)
+	if res.RequeueAfter <= 0 || actual.Status.Phase != v1alpha1.StepRunning || actual.Status.RunRetryCount != 1 ||
+		!strings.Contains(actual.Status.RunError, "resolve parametersFrom error") {
+		t.Fatalf("expect run retry counted, got %v %+v", res, actual.Status)
+	}
+	// 超过RunRetryLimit 后回滚，回滚同样计入重试次数
+	if _, actual = reconcileTestStep(t, r, step); actual.Status.Phase != v1alpha1.StepRollingBack {
+		t.Fatalf("expect step rolling back over runRetryLimit, got %+v", actual.Status)
+	}
+	if _, actual = reconcileTestStep(t, r, step); actual.Status.RollbackRetryCount != 1 || len(actual.Status.RollbackError) == 0 {
+		t.Fatalf("expect rollback retry counted, got %+v", actual.Status)
+	}
+	if _, actual = reconcileTestStep(t, r, step); actual.Status.Phase != v1alpha1.StepFailed {
+		t.Errorf("expect step failed over rollbackRetryLimit, got %+v", actual.Status)
+	}
+}
+
+func TestReconcileParametersFromErrorTimeout(t *testing.T) {
+	workflow, step := newTestStepWorkflow("login-test")
+	step.Spec.TimeoutSeconds = 10
+	step.Status.StartedAt = metav1.NewTime(time.Now().Add(-time.Minute))
+	step.Spec.Parameters = map[string]string{}
+	step.Spec.ParametersFrom = []v1alpha1.ParameterFrom{
+		{Name: "password", SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"}},
+	}
+	r := newTestStepReconciler(workflow, step)
+	// 解析parametersFrom 失败也不影响超时
+	_, actual := reconcileTestStep(t, r, step)
+	if actual.Status.Phase != v1alpha1.StepRollingBack || actual.Status.Reason != v1alpha1.TimeoutReason || actual.Status.RollbackRetryCount != 1 {
+		t.Errorf("expect step rolling back after timeout, got %+v", actual.Status)
+	}
+}
+
+func TestResolveParametersFromNotFound(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	step := &v1alpha1.Step{Spec: v1alpha1.StepSpec{
+		Parameters: map[string]string{},
+		ParametersFrom: []v1alpha1.ParameterFrom{
+			{Name: "password", SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"}},
+		},
+	}}
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	if _, err := resolveParametersFrom(context.Background(), c, step); err == nil || !strings.Contains(err.Error(), "parameter password") {
+		t.Errorf("expect error of parameter password, got %v", err)
+	}
+}
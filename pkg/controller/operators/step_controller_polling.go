@@ -0,0 +1,85 @@
+package operators
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
+)
+
+func (r *stepReconciler) reconcilePoll(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) {
+	log := r.log.WithValues("name", step.Name)
+	s, err := stepinterface.NewStepV2(r.controllerCtx.Config, workflow, step)
+	currentPhase := step.Status.Phase
+	if err != nil {
+		log.Error(err, "instantiate step error")
+		step.Status.Phase = v1alpha1.StepFailed
+		step.Status.RunError = err.Error()
+		r.recorder.Eventf(step, corev1.EventTypeNormal, v1alpha1.FailedOrErrorReason, "'%s' => '%s'%v",
+			currentPhase, v1alpha1.StepFailed, err)
+		return
+	}
+	asyncStep, ok := s.(stepinterface.AsyncStep)
+	if !ok {
+		// step 实现已不是异步的，重新执行Run
+		log.Info("step is not async, run it again")
+		step.Status.SubmittedAt = metav1.Time{}
+		return
+	}
+	r.runPoll(ctx, asyncStep, workflow, step)
+}
+
+func (r *stepReconciler) runPoll(ctx context.Context, s stepinterface.AsyncStep, workflow *v1alpha1.Workflow, step *v1alpha1.Step) {
+	log := r.log.WithValues("name", step.Name)
+	currentPhase := step.Status.Phase
+
+	log.V(4).Info("run step poll")
+	pollCtx, cancel := r.stepContext(ctx, workflow, step, true)
+	defer cancel()
+	done, stepErr := s.Poll(pollCtx, workflow, step)
+	// 轮询本身失败时任务可能还在执行，规则只决定是否计数，不会重新提交任务
+	pollFailed := stepErr != nil && stepErr.Retryable() && stepErr.Ignorable()
+	rule := matchRetryRule(step.Spec.RetryPolicy, v1alpha1.RunOperation, stepErr)
+	stepErr, skipCount := r.applyRetryRule(step, v1alpha1.RunOperation, rule, stepErr)
+	if rule != nil && rule.Action == v1alpha1.ActionSucceed {
+		done = true
+	}
+	step.Status.LatestPollAt = metav1.Now()
+	if stepErr != nil {
+		log.Error(r.redactError(stepErr), "step poll error")
+		step.Status.RunError = stepErr.Error()
+		// 轮询本身失败，任务可能还在执行，下次继续轮询
+		if pollFailed && stepErr.Retryable() {
+			r.recorder.Eventf(step, corev1.EventTypeNormal, v1alpha1.FailedOrErrorReason, "poll error: %v", stepErr)
+			return
+		}
+		if !stepErr.Ignorable() && !skipCount {
+			step.Status.RunRetryCount++
+		}
+		step.Status.LatestRunRetryAt = metav1.Now()
+		nextRunRetryAt(step)
+		retryRulePeriod(rule, step.Status.LatestRunRetryAt, &step.Status.NextRunRetryAt)
+		if !stepErr.Retryable() {
+			// 发现不可重试的错误，立即触发回滚
+			step.Status.Phase = v1alpha1.StepRollingBack
+			r.recorder.Eventf(step, corev1.EventTypeNormal, v1alpha1.FailedOrErrorReason, "'%s' => '%s',runRetryCount=%d error: %v",
+				currentPhase, v1alpha1.StepRollingBack, step.Status.RunRetryCount, stepErr)
+			return
+		}
+		// 任务失败但可重试，清理提交标记，由Run 重新提交
+		step.Status.SubmittedAt = metav1.Time{}
+		r.recorder.Eventf(step, corev1.EventTypeNormal, v1alpha1.FailedOrErrorReason, "runRetryCount=%d error: %v", step.Status.RunRetryCount, stepErr)
+		return
+	}
+	if !done {
+		return
+	}
+	// 清理掉之前可能的错误，规则的计数只作用于这一轮失败
+	step.Status.RunError = ""
+	step.Status.RetryCodeCounts = nil
+	step.Status.Phase = v1alpha1.StepSuccess
+	r.recorder.Eventf(step, corev1.EventTypeNormal, v1alpha1.PhaseChangeReason, "'%s' => '%s'", currentPhase, step.Status.Phase)
+}
@@ -0,0 +1,166 @@
+package operators
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
+	"github.com/qiankunli/workflow/pkg/options"
+)
+
+// pollStep 提交任务后按done/pollErr 返回轮询结果
+type pollStep struct {
+	submits int
+	done    bool
+	pollErr stepinterface.StepError
+}
+
+func (s *pollStep) Run(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	s.submits++
+	step.Status.Resource.ID = "job-1"
+	return nil
+}
+
+func (s *pollStep) Rollback(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	return nil
+}
+
+func (s *pollStep) Sync(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	return nil
+}
+
+func (s *pollStep) Poll(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (bool, stepinterface.StepError) {
+	return s.done, s.pollErr
+}
+
+func TestRunPoll(t *testing.T) {
+	cases := []struct {
+		name          string
+		done          bool
+		pollErr       stepinterface.StepError
+		expectPhase   v1alpha1.StepPhase
+		expectSubmit  bool
+		expectRetries int32
+	}{
+		{name: "running", expectPhase: v1alpha1.StepRunning, expectSubmit: true},
+		{name: "done", done: true, expectPhase: v1alpha1.StepSuccess, expectSubmit: true},
+		{name: "poll error", pollErr: stepinterface.NewStepError(errors.New("timeout"), true, true), expectPhase: v1alpha1.StepRunning, expectSubmit: true},
+		{name: "job failed", pollErr: stepinterface.NewStepError(errors.New("job failed"), true, false), expectPhase: v1alpha1.StepRunning, expectRetries: 1},
+		{name: "job failed not retryable", pollErr: stepinterface.NewStepError(errors.New("job failed"), false, false), expectPhase: v1alpha1.StepRollingBack, expectSubmit: true, expectRetries: 1},
+	}
+	for _, c := range cases {
+		r := newTestStepReconciler()
+		workflow, step := newTestStepWorkflow("poll-test")
+		step.Status.SubmittedAt = metav1.Now()
+		r.runPoll(context.Background(), &pollStep{done: c.done, pollErr: c.pollErr}, workflow, step)
+		if step.Status.Phase != c.expectPhase {
+			t.Errorf("%s: expect phase %s, got %s", c.name, c.expectPhase, step.Status.Phase)
+		}
+		if submitted := !step.Status.SubmittedAt.IsZero(); submitted != c.expectSubmit {
+			t.Errorf("%s: expect submitted %v, got %v", c.name, c.expectSubmit, submitted)
+		}
+		if step.Status.RunRetryCount != c.expectRetries {
+			t.Errorf("%s: expect runRetryCount %d, got %d", c.name, c.expectRetries, step.Status.RunRetryCount)
+		}
+		if step.Status.LatestPollAt.IsZero() {
+			t.Errorf("%s: expect latestPollAt recorded", c.name)
+		}
+	}
+}
+
+func TestStepReconcilePoll(t *testing.T) {
+	s := &pollStep{}
+	stepinterface.FactoryV2["poll-test"] = func(cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (stepinterface.StepV2, error) {
+		return s, nil
+	}
+	defer delete(stepinterface.FactoryV2, "poll-test")
+
+	workflow, step := newTestStepWorkflow("poll-test")
+	step.Spec.RetryPolicy.RunRetryLimit = 3
+	r := newTestStepReconciler(workflow, step)
+	// Run 只提交任务
+	if _, actual := reconcileTestStep(t, r, step); actual.Status.SubmittedAt.IsZero() || actual.Status.Resource.ID != "job-1" {
+		t.Fatalf("expect job submitted, got %+v", actual.Status)
+	}
+	// 任务失败但可重试，清理提交标记
+	s.pollErr = stepinterface.NewStepError(errors.New("job failed"), true, false)
+	if _, actual := reconcileTestStep(t, r, step); !actual.Status.SubmittedAt.IsZero() || actual.Status.Phase != v1alpha1.StepRunning {
+		t.Fatalf("expect job to be submitted again, got %+v", actual.Status)
+	}
+	// 由Run 重新提交
+	s.pollErr = nil
+	if _, actual := reconcileTestStep(t, r, step); s.submits != 2 || actual.Status.SubmittedAt.IsZero() {
+		t.Fatalf("expect job submitted twice, got %d %+v", s.submits, actual.Status)
+	}
+	s.done = true
+	if _, actual := reconcileTestStep(t, r, step); actual.Status.Phase != v1alpha1.StepSuccess || len(actual.Status.RunError) > 0 {
+		t.Errorf("expect step success, got %+v", actual.Status)
+	}
+}
+
+func TestRunPollRetryRule(t *testing.T) {
+	retry := v1alpha1.RetryRule{Code: "THROTTLED", Action: v1alpha1.ActionRetry, RetryLimit: 2, RetryPeriodSeconds: 30}
+	ignore := v1alpha1.RetryRule{Code: "THROTTLED", Action: v1alpha1.ActionIgnore}
+	jobFailed := stepinterface.NewCodeError("THROTTLED", "job throttled", true, false)
+	pollFailed := stepinterface.NewCodeError("THROTTLED", "poll throttled", true, true)
+	cases := []struct {
+		name         string
+		rule         v1alpha1.RetryRule
+		pollErr      stepinterface.StepError
+		expectSubmit bool
+	}{
+		// 任务失败，由Run 重新提交
+		{name: "retry job failed", rule: retry, pollErr: jobFailed},
+		{name: "ignore job failed", rule: ignore, pollErr: jobFailed},
+		// 轮询本身失败，继续轮询已提交的任务
+		{name: "retry poll failed", rule: retry, pollErr: pollFailed, expectSubmit: true},
+		{name: "ignore poll failed", rule: ignore, pollErr: pollFailed, expectSubmit: true},
+	}
+	for _, c := range cases {
+		r := newTestStepReconciler()
+		workflow, step := newTestStepWorkflow("poll-test")
+		step.Spec.RetryPolicy.Rules = []v1alpha1.RetryRule{c.rule}
+		step.Status.SubmittedAt = metav1.Now()
+		s := &pollStep{pollErr: c.pollErr}
+		r.runPoll(context.Background(), s, workflow, step)
+		// 不计入RunRetryCount
+		if step.Status.Phase != v1alpha1.StepRunning || step.Status.RunRetryCount != 0 {
+			t.Errorf("%s: expect step running without runRetryCount, got %+v", c.name, step.Status)
+		}
+		if submitted := !step.Status.SubmittedAt.IsZero(); submitted != c.expectSubmit {
+			t.Errorf("%s: expect submitted %v, got %v", c.name, c.expectSubmit, submitted)
+		}
+		if !c.expectSubmit && c.rule.RetryPeriodSeconds > 0 &&
+			!step.Status.NextRunRetryAt.Equal(&metav1.Time{Time: step.Status.LatestRunRetryAt.Add(30 * time.Second)}) {
+			t.Errorf("%s: expect retry period of rule, got %v", c.name, step.Status.NextRunRetryAt)
+		}
+		// 成功后重新计数
+		s.pollErr = nil
+		s.done = true
+		r.runPoll(context.Background(), s, workflow, step)
+		if step.Status.Phase != v1alpha1.StepSuccess || len(step.Status.RetryCodeCounts) > 0 {
+			t.Errorf("%s: expect step success and retry code counts reset, got %+v", c.name, step.Status)
+		}
+	}
+}
+
+func TestRunPollRetryRuleOverLimit(t *testing.T) {
+	r := newTestStepReconciler()
+	workflow, step := newTestStepWorkflow("poll-test")
+	step.Spec.RetryPolicy.Rules = []v1alpha1.RetryRule{{Code: "THROTTLED", Action: v1alpha1.ActionRetry, RetryLimit: 1}}
+	step.Status.SubmittedAt = metav1.Now()
+	s := &pollStep{pollErr: stepinterface.NewCodeError("THROTTLED", "poll throttled", true, true)}
+	r.runPoll(context.Background(), s, workflow, step)
+	if step.Status.Phase != v1alpha1.StepRunning || step.Status.SubmittedAt.IsZero() {
+		t.Fatalf("expect step polled again, got %+v", step.Status)
+	}
+	r.runPoll(context.Background(), s, workflow, step)
+	if step.Status.Phase != v1alpha1.StepRollingBack {
+		t.Errorf("expect step rolling back over retry limit, got %+v", step.Status)
+	}
+}
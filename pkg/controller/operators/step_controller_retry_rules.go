@@ -0,0 +1,76 @@
+package operators
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
+)
+
+// matchRetryRule 按顺序找到第一条匹配stepErr code 的规则，没有则返回nil
+var matchRetryRule = func(policy v1alpha1.RetryPolicy, operation v1alpha1.RetryOperation, stepErr stepinterface.StepError) *v1alpha1.RetryRule {
+	if stepErr == nil || len(policy.Rules) == 0 {
+		return nil
+	}
+	code := stepinterface.ErrorCode(stepErr)
+	if len(code) == 0 {
+		return nil
+	}
+	for i := range policy.Rules {
+		rule := &policy.Rules[i]
+		if rule.Code != code {
+			continue
+		}
+		if len(rule.Operation) > 0 && rule.Operation != operation {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+// applyRetryRule 按匹配的规则覆盖step 作者设置的retryable，规则视为成功时返回nil，skipCount 为true 时不计入RunRetryCount/RollbackRetryCount
+func (r *stepReconciler) applyRetryRule(step *v1alpha1.Step, operation v1alpha1.RetryOperation, rule *v1alpha1.RetryRule, stepErr stepinterface.StepError) (result stepinterface.StepError, skipCount bool) {
+	if rule == nil {
+		return stepErr, false
+	}
+	log := r.log.WithValues("name", step.Name)
+	log.V(4).Info("step error matches retry rule", "operation", operation, "code", rule.Code, "action", rule.Action)
+	// 规则作用于任务本身的失败，不设置ignorable，以免异步step 被当作轮询失败而不重新提交
+	switch rule.Action {
+	case v1alpha1.ActionSucceed:
+		r.recorder.Eventf(step, corev1.EventTypeNormal, v1alpha1.FailedOrErrorReason, "%s error treated as success by retry rule: %v", operation, stepErr)
+		return nil, false
+	case v1alpha1.ActionFail:
+		return stepinterface.NewStepError(stepErr, false, false), false
+	case v1alpha1.ActionIgnore:
+		return stepinterface.NewStepError(stepErr, true, false), true
+	case v1alpha1.ActionRetry:
+		if rule.RetryLimit <= 0 {
+			return stepinterface.NewStepError(stepErr, true, false), false
+		}
+		// 单独计数，不占用RunRetryLimit/RollbackRetryLimit
+		if step.Status.RetryCodeCounts == nil {
+			step.Status.RetryCodeCounts = map[string]int32{}
+		}
+		key := fmt.Sprintf("%s/%s", operation, rule.Code)
+		step.Status.RetryCodeCounts[key]++
+		if step.Status.RetryCodeCounts[key] > rule.RetryLimit {
+			return stepinterface.NewStepError(fmt.Errorf("over retry limit %d of code %s: %v", rule.RetryLimit, rule.Code, stepErr), false, false), true
+		}
+		return stepinterface.NewStepError(stepErr, true, false), true
+	}
+	return stepErr, false
+}
+
+// retryRulePeriod 规则设置了retryPeriodSeconds 时覆盖按退避策略计算出的下次重试时间
+var retryRulePeriod = func(rule *v1alpha1.RetryRule, latestRetryAt metav1.Time, nextRetryAt *metav1.Time) {
+	if rule == nil || rule.Action != v1alpha1.ActionRetry || rule.RetryPeriodSeconds <= 0 {
+		return
+	}
+	*nextRetryAt = metav1.NewTime(latestRetryAt.Add(time.Duration(rule.RetryPeriodSeconds) * time.Second))
+}
@@ -0,0 +1,103 @@
+package operators
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
+)
+
+func TestApplyRetryRule(t *testing.T) {
+	r := &stepReconciler{
+		log:      logr.Discard(),
+		recorder: record.NewFakeRecorder(10),
+	}
+	policy := v1alpha1.RetryPolicy{
+		Rules: []v1alpha1.RetryRule{
+			{Code: "THROTTLED", Action: v1alpha1.ActionRetry, RetryLimit: 2},
+			{Code: "NOT_FOUND", Operation: v1alpha1.RollbackOperation, Action: v1alpha1.ActionSucceed},
+			{Code: "INVALID", Action: v1alpha1.ActionFail},
+		},
+	}
+	step := &v1alpha1.Step{Spec: v1alpha1.StepSpec{RetryPolicy: policy}}
+
+	// NOT_FOUND 只作用于回滚
+	notFound := stepinterface.NewCodeError("NOT_FOUND", "not found", false, false)
+	if rule := matchRetryRule(policy, v1alpha1.RunOperation, notFound); rule != nil {
+		t.Fatalf("expect no rule for run, got %v", rule)
+	}
+	rule := matchRetryRule(policy, v1alpha1.RollbackOperation, notFound)
+	if stepErr, _ := r.applyRetryRule(step, v1alpha1.RollbackOperation, rule, notFound); stepErr != nil {
+		t.Fatalf("expect NOT_FOUND treated as success, got %v", stepErr)
+	}
+
+	invalid := stepinterface.NewCodeError("INVALID", "invalid", true, false)
+	rule = matchRetryRule(policy, v1alpha1.RunOperation, invalid)
+	if stepErr, _ := r.applyRetryRule(step, v1alpha1.RunOperation, rule, invalid); stepErr == nil || stepErr.Retryable() {
+		t.Fatalf("expect INVALID not retryable, got %v", stepErr)
+	}
+
+	// THROTTLED 单独计数，前两次可重试且不计入RunRetryCount
+	throttled := stepinterface.NewCodeError("THROTTLED", "throttled", false, false)
+	for i := 0; i < 2; i++ {
+		rule = matchRetryRule(policy, v1alpha1.RunOperation, throttled)
+		stepErr, skipCount := r.applyRetryRule(step, v1alpha1.RunOperation, rule, throttled)
+		// 不是ignorable，异步step 会重新提交任务
+		if stepErr == nil || !stepErr.Retryable() || stepErr.Ignorable() || !skipCount {
+			t.Fatalf("expect THROTTLED retryable and not counted, got %v %v", stepErr, skipCount)
+		}
+	}
+	rule = matchRetryRule(policy, v1alpha1.RunOperation, throttled)
+	if stepErr, _ := r.applyRetryRule(step, v1alpha1.RunOperation, rule, throttled); stepErr == nil || stepErr.Retryable() {
+		t.Fatalf("expect THROTTLED over retry limit not retryable, got %v", stepErr)
+	}
+	if count := step.Status.RetryCodeCounts["Run/THROTTLED"]; count != 3 {
+		t.Fatalf("expect THROTTLED count 3, got %d", count)
+	}
+
+	// 没有code 的错误不匹配任何规则
+	plain := stepinterface.NewStepError(stepinterface.NewCodeError("THROTTLED", "x", false, false), false, false)
+	if rule = matchRetryRule(policy, v1alpha1.RunOperation, plain); rule != nil {
+		t.Fatalf("expect no rule for error without code, got %v", rule)
+	}
+}
+
+// throttledStep Run 按err 返回
+type throttledStep struct {
+	err stepinterface.StepError
+}
+
+func (s *throttledStep) Run(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	return s.err
+}
+
+func (s *throttledStep) Rollback(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	return nil
+}
+
+func (s *throttledStep) Sync(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	return nil
+}
+
+func TestRunRunResetRetryCodeCounts(t *testing.T) {
+	r := newTestStepReconciler()
+	workflow, step := newTestStepWorkflow("throttled-test")
+	step.Spec.RetryPolicy.Rules = []v1alpha1.RetryRule{{Code: "THROTTLED", Action: v1alpha1.ActionRetry, RetryLimit: 2}}
+	s := &throttledStep{err: stepinterface.NewCodeError("THROTTLED", "throttled", false, false)}
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		r.runRun(ctx, s, workflow, step)
+	}
+	if step.Status.Phase != v1alpha1.StepRunning || step.Status.RetryCodeCounts["Run/THROTTLED"] != 2 || step.Status.RunRetryCount != 0 {
+		t.Fatalf("expect THROTTLED retried without runRetryCount, got %+v", step.Status)
+	}
+	s.err = nil
+	r.runRun(ctx, s, workflow, step)
+	if step.Status.Phase != v1alpha1.StepSuccess || len(step.Status.RetryCodeCounts) > 0 {
+		t.Errorf("expect retry code counts reset after success, got %+v", step.Status)
+	}
+}
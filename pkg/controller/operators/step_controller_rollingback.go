@@ -10,9 +10,9 @@ import (
 	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
 )
 
-func (r *stepReconciler) reconcileRollback(_ context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) {
+func (r *stepReconciler) reconcileRollback(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) {
 	log := r.log.WithValues("name", step.Name)
-	s, err := stepinterface.NewStep(r.controllerCtx.Config, workflow, step)
+	s, err := stepinterface.NewStepV2(r.controllerCtx.Config, workflow, step)
 	currentPhase := step.Status.Phase
 	if err != nil {
 		log.Error(err, "instantiate step error")
@@ -30,20 +30,27 @@ func (r *stepReconciler) reconcileRollback(_ context.Context, workflow *v1alpha1
 			currentPhase, v1alpha1.StepFailed)
 		return
 	}
-	r.runRollback(s, workflow, step)
+	r.runRollback(ctx, s, workflow, step)
 }
 
-func (r *stepReconciler) runRollback(s stepinterface.Step, workflow *v1alpha1.Workflow, step *v1alpha1.Step) {
+func (r *stepReconciler) runRollback(ctx context.Context, s stepinterface.StepV2, workflow *v1alpha1.Workflow, step *v1alpha1.Step) {
 	log := r.log.WithValues("name", step.Name)
 	currentPhase := step.Status.Phase
 	log.V(4).Info("run step rollback")
-	stepErr := s.Rollback(workflow, step)
-	if stepErr != nil && !stepErr.Ignorable() {
+	// workflow 删除时正是需要回滚的时候，所以回滚不随workflow 删除而cancel
+	rollbackCtx, cancel := r.stepContext(ctx, workflow, step, false)
+	defer cancel()
+	stepErr := s.Rollback(rollbackCtx, workflow, step)
+	rule := matchRetryRule(step.Spec.RetryPolicy, v1alpha1.RollbackOperation, stepErr)
+	stepErr, skipCount := r.applyRetryRule(step, v1alpha1.RollbackOperation, rule, stepErr)
+	if stepErr != nil && !stepErr.Ignorable() && !skipCount {
 		step.Status.RollbackRetryCount++
 	}
 	step.Status.LatestRollbackRetryAt = metav1.Now()
+	nextRollbackRetryAt(step)
+	retryRulePeriod(rule, step.Status.LatestRollbackRetryAt, &step.Status.NextRollbackRetryAt)
 	if stepErr != nil {
-		log.Error(stepErr, "step rollback error")
+		log.Error(r.redactError(stepErr), "step rollback error")
 		step.Status.RollbackError = stepErr.Error()
 		if !stepErr.Retryable() {
 			step.Status.Phase = v1alpha1.StepFailed
@@ -54,8 +61,9 @@ func (r *stepReconciler) runRollback(s stepinterface.Step, workflow *v1alpha1.Wo
 		}
 		return
 	}
-	// 清理掉之前可能的错误
+	// 清理掉之前可能的错误，规则的计数只作用于这一轮失败
 	step.Status.RollbackError = ""
+	step.Status.RetryCodeCounts = nil
 	step.Status.Phase = v1alpha1.StepRollBacked
 	r.recorder.Eventf(step, corev1.EventTypeNormal, v1alpha1.PhaseChangeReason, "'%s' => '%s'", currentPhase, v1alpha1.StepRollBacked)
 }
@@ -10,9 +10,9 @@ import (
 	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
 )
 
-func (r *stepReconciler) reconcileRun(_ context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) {
+func (r *stepReconciler) reconcileRun(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) {
 	log := r.log.WithValues("name", step.Name)
-	s, err := stepinterface.NewStep(r.controllerCtx.Config, workflow, step)
+	s, err := stepinterface.NewStepV2(r.controllerCtx.Config, workflow, step)
 	currentPhase := step.Status.Phase
 	if err != nil {
 		log.Error(err, "instantiate step error")
@@ -30,10 +30,10 @@ func (r *stepReconciler) reconcileRun(_ context.Context, workflow *v1alpha1.Work
 			currentPhase, step.Status.Phase)
 		return
 	}
-	r.runRun(s, workflow, step)
+	r.runRun(ctx, s, workflow, step)
 }
 
-func (r *stepReconciler) runRun(s stepinterface.Step, workflow *v1alpha1.Workflow, step *v1alpha1.Step) {
+func (r *stepReconciler) runRun(ctx context.Context, s stepinterface.StepV2, workflow *v1alpha1.Workflow, step *v1alpha1.Step) {
 	log := r.log.WithValues("name", step.Name)
 	currentPhase := step.Status.Phase
 
@@ -43,13 +43,19 @@ func (r *stepReconciler) runRun(s stepinterface.Step, workflow *v1alpha1.Workflo
 	}
 
 	log.V(4).Info("run step run")
-	stepErr := s.Run(workflow, step)
-	if stepErr != nil && !stepErr.Ignorable() {
+	runCtx, cancel := r.stepContext(ctx, workflow, step, true)
+	defer cancel()
+	stepErr := s.Run(runCtx, workflow, step)
+	rule := matchRetryRule(step.Spec.RetryPolicy, v1alpha1.RunOperation, stepErr)
+	stepErr, skipCount := r.applyRetryRule(step, v1alpha1.RunOperation, rule, stepErr)
+	if stepErr != nil && !stepErr.Ignorable() && !skipCount {
 		step.Status.RunRetryCount++
 	}
 	step.Status.LatestRunRetryAt = metav1.Now()
+	nextRunRetryAt(step)
+	retryRulePeriod(rule, step.Status.LatestRunRetryAt, &step.Status.NextRunRetryAt)
 	if stepErr != nil {
-		log.Error(stepErr, "step run error")
+		log.Error(r.redactError(stepErr), "step run error")
 		step.Status.RunError = stepErr.Error()
 		if !stepErr.Retryable() {
 			// 发现不可重试的错误，立即触发回滚
@@ -61,8 +67,16 @@ func (r *stepReconciler) runRun(s stepinterface.Step, workflow *v1alpha1.Workflo
 		}
 		return
 	}
-	// 清理掉之前可能的错误
+	// 异步step 只是提交了任务，之后轮询任务状态
+	if _, ok := s.(stepinterface.AsyncStep); ok {
+		step.Status.SubmittedAt = metav1.Now()
+		step.Status.LatestPollAt = metav1.Time{}
+		r.recorder.Eventf(step, corev1.EventTypeNormal, v1alpha1.SubmittedReason, "job submitted, resource id=%s", step.Status.Resource.ID)
+		return
+	}
+	// 清理掉之前可能的错误，规则的计数只作用于这一轮失败
 	step.Status.RunError = ""
+	step.Status.RetryCodeCounts = nil
 	step.Status.Phase = v1alpha1.StepSuccess
 	r.recorder.Eventf(step, corev1.EventTypeNormal, v1alpha1.PhaseChangeReason, "'%s' => '%s'", currentPhase, step.Status.Phase)
 }
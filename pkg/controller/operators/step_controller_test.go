@@ -0,0 +1,150 @@
+package operators
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/controller/manager"
+	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
+	"github.com/qiankunli/workflow/pkg/options"
+	"github.com/qiankunli/workflow/pkg/utils/cancel"
+	"github.com/qiankunli/workflow/pkg/utils/mutex"
+)
+
+// newTestStepReconciler 使用fake client 的stepReconciler
+func newTestStepReconciler(objs ...client.Object) *stepReconciler {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+	return &stepReconciler{
+		client:    fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		log:       logr.Discard(),
+		recorder:  record.NewFakeRecorder(10),
+		StepMutex: mutex.NewGroupMutex(),
+		controllerCtx: &manager.ControllerContext{
+			Config:       options.NewDefaultConfig(),
+			StepCanceler: cancel.NewGroupCanceler(),
+		},
+	}
+}
+
+// newTestStepWorkflow workflow example 和它的Running step a
+func newTestStepWorkflow(stepType string) (*v1alpha1.Workflow, *v1alpha1.Step) {
+	workflow := &v1alpha1.Workflow{}
+	workflow.Name = "example"
+	workflow.Namespace = "default"
+	step := &v1alpha1.Step{Spec: v1alpha1.StepSpec{
+		Type:        stepType,
+		RetryPolicy: v1alpha1.RetryPolicy{RunRetryLimit: 1},
+	}}
+	step.Name = "example-a"
+	step.Namespace = "default"
+	step.Labels = map[string]string{"workflow": "example", "step": "a"}
+	step.Status.Phase = v1alpha1.StepRunning
+	return workflow, step
+}
+
+// reconcileTestStep reconcile 一次并返回最新的step
+func reconcileTestStep(t *testing.T, r *stepReconciler, step *v1alpha1.Step) (ctrl.Result, *v1alpha1.Step) {
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(step)}
+	res, err := r.Reconcile(ctx, req)
+	if err != nil {
+		t.Fatalf("reconcile error: %v", err)
+	}
+	actual := &v1alpha1.Step{}
+	if err = r.client.Get(ctx, req.NamespacedName, actual); err != nil {
+		t.Fatalf("get step error: %v", err)
+	}
+	return res, actual
+}
+
+func TestStepReconcileSuspended(t *testing.T) {
+	stepinterface.FactoryV2["login-test"] = func(cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (stepinterface.StepV2, error) {
+		return &loginStep{}, nil
+	}
+	defer delete(stepinterface.FactoryV2, "login-test")
+
+	workflow, step := newTestStepWorkflow("login-test")
+	workflow.Spec.Suspend = true
+	r := newTestStepReconciler(workflow, step)
+	res, actual := reconcileTestStep(t, r, step)
+	if res.RequeueAfter <= 0 || actual.Status.Phase != v1alpha1.StepRunning || len(actual.Status.RunError) > 0 {
+		t.Fatalf("expect step held while suspended, got %v %+v", res, actual.Status)
+	}
+
+	// 恢复后继续Run
+	ctx := context.Background()
+	current := &v1alpha1.Workflow{}
+	if err := r.client.Get(ctx, client.ObjectKeyFromObject(workflow), current); err != nil {
+		t.Fatalf("get workflow error: %v", err)
+	}
+	current.Spec.Suspend = false
+	if err := r.client.Update(ctx, current); err != nil {
+		t.Fatalf("update workflow error: %v", err)
+	}
+	if _, actual = reconcileTestStep(t, r, step); len(actual.Status.RunError) == 0 {
+		t.Errorf("expect step run after resumed, got %+v", actual.Status)
+	}
+}
+
+func TestReconcileTimeout(t *testing.T) {
+	r := newTestStepReconciler()
+	_, step := newTestStepWorkflow("empty")
+	// 没有设置timeoutSeconds 不会超时
+	step.Status.StartedAt = metav1.NewTime(time.Now().Add(-time.Hour))
+	r.reconcileTimeout(step)
+	if step.Status.Phase != v1alpha1.StepRunning {
+		t.Fatalf("expect step running without timeoutSeconds, got %s", step.Status.Phase)
+	}
+	step.Spec.TimeoutSeconds = 10
+	step.Status.StartedAt = metav1.Now()
+	r.reconcileTimeout(step)
+	if step.Status.Phase != v1alpha1.StepRunning {
+		t.Fatalf("expect step running before timeout, got %s", step.Status.Phase)
+	}
+	step.Status.StartedAt = metav1.NewTime(time.Now().Add(-time.Minute))
+	r.reconcileTimeout(step)
+	if step.Status.Phase != v1alpha1.StepRollingBack || step.Status.Reason != v1alpha1.TimeoutReason || len(step.Status.RunError) == 0 {
+		t.Errorf("expect step rolling back after timeout, got %+v", step.Status)
+	}
+}
+
+func TestStepReconcileTimeout(t *testing.T) {
+	workflow, step := newTestStepWorkflow("empty")
+	step.Spec.TimeoutSeconds = 10
+	step.Status.StartedAt = metav1.NewTime(time.Now().Add(-time.Minute))
+	r := newTestStepReconciler(workflow, step)
+	// 超时后不再Run，直接回滚
+	_, actual := reconcileTestStep(t, r, step)
+	if actual.Status.Phase != v1alpha1.StepRollBacked || actual.Status.Reason != v1alpha1.TimeoutReason || actual.Status.RunRetryCount != 0 {
+		t.Errorf("expect step rollbacked after timeout, got %+v", actual.Status)
+	}
+}
+
+func TestCapByStepTimeout(t *testing.T) {
+	_, step := newTestStepWorkflow("empty")
+	if d := capByStepTimeout(step, time.Minute); d != time.Minute {
+		t.Errorf("expect no cap without timeoutSeconds, got %v", d)
+	}
+	step.Spec.TimeoutSeconds = 10
+	step.Status.StartedAt = metav1.Now()
+	if d := capByStepTimeout(step, time.Second); d != time.Second {
+		t.Errorf("expect no cap before timeout, got %v", d)
+	}
+	// 不超过超时时间再多1s
+	if d := capByStepTimeout(step, time.Minute); d > 11*time.Second || d < 10*time.Second {
+		t.Errorf("expect requeue capped by timeout, got %v", d)
+	}
+}
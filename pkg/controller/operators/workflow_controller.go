@@ -115,6 +115,15 @@ func (r *workflowReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 			reterr = k8sutilerrors.NewAggregate([]error{reterr, err})
 		}
 	}()
+	// 引用模板的workflow 按status 中的快照运行，patch 之前恢复spec，避免把快照写回spec
+	if workflow.Spec.WorkflowTemplateRef != nil {
+		spec := workflow.Spec.DeepCopy()
+		defer func() { workflow.Spec = *spec }()
+		if err = r.reconcileTemplate(ctx, workflow); err != nil {
+			log.Error(err, "failed to resolve workflow template")
+			return ctrl.Result{}, err
+		}
+	}
 
 	steps, err := r.GetStepsForWorkflow(workflow)
 	if err != nil {
@@ -123,9 +132,22 @@ func (r *workflowReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 	}
 	// 根据step 状态更新下workflow 状态以便决定下一步逻辑
 	r.aggregateStepStatus(ctx, workflow, steps)
+	// 重试Failed 的step，下次reconcile 按step 的新状态继续
+	if r.reconcileRetry(ctx, workflow, steps) {
+		return ctrl.Result{RequeueAfter: constants.DefaultRequeueDuration}, nil
+	}
 	if !workflow.DeletionTimestamp.IsZero() {
 		log.V(4).Info("workflow deletionTimestamp is not zero", "phase", workflow.Status.Phase)
-		if seeAsRollBackedWorkflow(workflow) {
+		// cancel 正在执行的step Run/Sync
+		r.controllerCtx.StepCanceler.Cancel(lockKey)
+		if skipRollback(workflow) {
+			// 去掉step 的finalizer，step 随workflow 一起被删除
+			if err = r.releaseSteps(ctx, steps); err != nil {
+				log.Error(err, "release steps error")
+				return ctrl.Result{RequeueAfter: constants.DefaultRequeueDuration}, nil
+			}
+		}
+		if seeAsRollBackedWorkflow(workflow) || skipRollback(workflow) {
 			if err = r.onDeleted(ctx, workflow); err != nil {
 				return ctrl.Result{RequeueAfter: constants.DefaultRequeueDuration}, nil
 			}
@@ -144,7 +166,7 @@ func (r *workflowReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 				r.reconcileRollingBack(ctx, workflow, steps)
 				return ctrl.Result{RequeueAfter: constants.DefaultRequeueDuration}, nil
 			}
-			// failed之后，建议人工介入处理，无论wf 还是step 都不会对failed 状态再施加操作，否则逻辑太复杂了
+			// failed之后，建议人工介入处理，无论wf 还是step 都不会对failed 状态再施加操作，否则逻辑太复杂了。处理完成后可以通过retry annotation 重试
 			return ctrl.Result{}, nil
 		}
 		// 回滚中
@@ -154,9 +176,24 @@ func (r *workflowReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 	if !controllerutil.ContainsFinalizer(workflow, constants.FinalizersWorkflow) {
 		controllerutil.AddFinalizer(workflow, constants.FinalizersWorkflow)
 	}
+	r.reconcileSuspend(workflow)
+	if r.reconcileDesiredState(ctx, workflow, steps) {
+		// 开始回滚，要一会儿再进来看下
+		return ctrl.Result{RequeueAfter: constants.DefaultRequeueDuration}, nil
+	}
+	if workflow.Status.Phase == v1alpha1.WorkflowRunning && r.reconcileDeadline(ctx, workflow, steps) {
+		// 超时开始回滚，要一会儿再进来看下
+		return ctrl.Result{RequeueAfter: constants.DefaultRequeueDuration}, nil
+	}
+	if workflow.Status.Phase == v1alpha1.WorkflowRunning && r.reconcileSpecWrong(ctx, workflow, steps) {
+		return ctrl.Result{RequeueAfter: constants.DefaultRequeueDuration}, nil
+	}
 	if workflow.Status.Phase == v1alpha1.WorkflowRunning {
 		r.reconcileCreating(ctx, workflow, steps)
-		r.reconcileRunning(ctx, workflow, steps)
+		// withParam 无法展开时workflow 开始回滚
+		if workflow.Status.Phase == v1alpha1.WorkflowRunning {
+			r.reconcileRunning(ctx, workflow, steps)
+		}
 		if err = r.onStart(ctx, workflow); err != nil {
 			return ctrl.Result{RequeueAfter: constants.DefaultRequeueDuration}, nil
 		}
@@ -193,7 +230,8 @@ func (r *workflowReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 func (r *workflowReconciler) aggregateStepStatus(ctx context.Context, workflow *v1alpha1.Workflow, steps []v1alpha1.Step) {
 	log := r.log.WithValues("name", workflow.Name)
 	currentPhase := workflow.Status.Phase
-	if len(steps) == 0 {
+	// withParam 展开为空列表的step 不会创建
+	if len(steps) == 0 && len(workflow.Status.StepItemCounts) == 0 {
 		log.V(4).Info("can not find steps for workflow")
 		return
 	}
@@ -201,7 +239,6 @@ func (r *workflowReconciler) aggregateStepStatus(ctx context.Context, workflow *
 	runErrors := make([]string, 0)
 	rollbackErrors := make([]string, 0)
 	syncErrors := make([]string, 0)
-	stepAttributes := map[string]string{}
 	for _, step := range steps {
 		count[step.Status.Phase]++
 		if len(step.Status.RunError) > 0 {
@@ -213,12 +250,11 @@ func (r *workflowReconciler) aggregateStepStatus(ctx context.Context, workflow *
 		if len(step.Status.SyncError) > 0 {
 			syncErrors = append(syncErrors, fmt.Sprintf("%s:%s", step.Spec.Type, step.Status.SyncError))
 		}
-		for k, v := range step.Status.Attributes {
-			stepAttributes[k] = v
-		}
 	}
 	workflow.Status.StepPhases = count
-	workflow.Status.Attributes = stepAttributes
+	// exportAttributes 写入冲突由reconcileSpecWrong 回滚workflow
+	workflow.Status.StepOutputs, workflow.Status.Attributes, _ = collectOutputs(workflow, steps)
+	workflow.Status.Outputs = collectJSONOutputs(workflow, steps)
 	if len(runErrors) > 0 {
 		workflow.Status.RunError = strings.Join(runErrors, "\n")
 	}
@@ -234,14 +270,20 @@ func (r *workflowReconciler) aggregateStepStatus(ctx context.Context, workflow *
 		// 仅触发一次，不管成功失败，都走下一步流程
 		_ = r.onChange(ctx, workflow)
 	}
-	// 所有step 都成功了，则标记自己为成功
-	if count[v1alpha1.StepSuccess] == len(steps) {
+	// 所有step 都创建了并且都成功或被跳过了，则标记自己为成功。要求回滚的workflow 不再回到Success
+	if count[v1alpha1.StepSuccess]+count[v1alpha1.StepSkipped] == len(steps) && allStepsCreated(workflow, groupSteps(steps)) &&
+		!rollbackRequested(workflow) {
 		workflow.Status.Phase = v1alpha1.WorkflowSuccess
 		if currentPhase != v1alpha1.WorkflowSuccess {
 			r.recorder.Eventf(workflow, corev1.EventTypeNormal, v1alpha1.PhaseChangeReason, "'%s' => '%s'", currentPhase, workflow.Status.Phase)
 		}
 		return
 	}
+	// 处理失败的step 运行结束后，workflow 才失败或回滚
+	if currentPhase == v1alpha1.WorkflowRunning && workflow.DeletionTimestamp.IsZero() && !rollbackRequested(workflow) &&
+		handlingFailure(workflow, steps) {
+		return
+	}
 	// 有step失败，则标记为失败
 	if count[v1alpha1.StepFailed] > 0 {
 		workflow.Status.Phase = v1alpha1.WorkflowFailed
@@ -250,8 +292,8 @@ func (r *workflowReconciler) aggregateStepStatus(ctx context.Context, workflow *
 		}
 		return
 	}
-	// 所有step都回滚了，则标记回滚完成
-	if count[v1alpha1.StepRollBacked] == len(steps) {
+	// 所有step都回滚或被跳过了，则标记回滚完成
+	if count[v1alpha1.StepRollBacked]+count[v1alpha1.StepSkipped] == len(steps) {
 		workflow.Status.Phase = v1alpha1.WorkflowRollBacked
 		if currentPhase != v1alpha1.WorkflowRollBacked {
 			r.recorder.Eventf(workflow, corev1.EventTypeNormal, v1alpha1.PhaseChangeReason, "'%s' => '%s'", currentPhase, workflow.Status.Phase)
@@ -287,13 +329,39 @@ func (r *workflowReconciler) GetStepsForWorkflow(workflow *v1alpha1.Workflow) ([
 	return stepList.Items, nil
 }
 
+// releaseSteps 去掉step 的finalizer，不再等待step 回滚
+func (r *workflowReconciler) releaseSteps(ctx context.Context, steps []v1alpha1.Step) error {
+	for i := range steps {
+		step := &steps[i]
+		if !controllerutil.ContainsFinalizer(step, constants.FinalizersWorkflow) {
+			continue
+		}
+		patch := client.MergeFrom(step.DeepCopy())
+		controllerutil.RemoveFinalizer(step, constants.FinalizersWorkflow)
+		if err := r.client.Patch(ctx, step, patch); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipRollback 带有skip-rollback 注解的成功workflow 删除时不回滚
+var skipRollback = func(workflow *v1alpha1.Workflow) bool {
+	return workflow.Annotations[constants.AnnotationSkipRollback] == "true" && workflow.Status.Phase == v1alpha1.WorkflowSuccess
+}
+
 var seeAsRollBackedWorkflow = func(workflow *v1alpha1.Workflow) bool {
 	// 都回滚完成了，才开始真正删除
 	if workflow.Status.Phase == v1alpha1.WorkflowRollBacked {
 		return true
 	}
+	// step 都被跳过了，没有需要回滚的step
+	if stepCount(workflow) > 0 && workflow.Status.StepPhases[v1alpha1.StepSkipped] == stepCount(workflow) {
+		return true
+	}
 	if workflow.Spec.RollbackPolicy == v1alpha1.Always &&
-		workflow.Status.StepPhases[v1alpha1.StepFailed]+workflow.Status.StepPhases[v1alpha1.StepRollBacked] == len(workflow.Spec.Steps) {
+		workflow.Status.StepPhases[v1alpha1.StepFailed]+workflow.Status.StepPhases[v1alpha1.StepRollBacked]+
+			workflow.Status.StepPhases[v1alpha1.StepSkipped] == stepCount(workflow) {
 		return true
 	}
 	return false
@@ -306,7 +374,7 @@ var seeAsRollingBackWorkflow = func(workflow *v1alpha1.Workflow) bool {
 	if rollbackCount == 0 {
 		return false
 	}
-	if workflow.Spec.RollbackPolicy == v1alpha1.Always && rollbackCount < len(workflow.Spec.Steps) {
+	if workflow.Spec.RollbackPolicy == v1alpha1.Always && rollbackCount+workflow.Status.StepPhases[v1alpha1.StepSkipped] < stepCount(workflow) {
 		return true
 	}
 	return false
@@ -3,6 +3,7 @@ package operators
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -11,21 +12,50 @@ import (
 
 func (r *workflowReconciler) reconcileCreating(ctx context.Context, workflow *v1alpha1.Workflow, steps []v1alpha1.Step) {
 	// 去重
-	stepSet := make(map[string]bool, 0)
-	for _, s := range steps {
-		stepSet[s.Name] = true
-	}
+	groups := groupSteps(steps)
 	for _, ws := range workflow.Spec.Steps {
-		if stepSet[ws.Name] {
+		if !isFanOut(ws) {
+			if len(groups[ws.Name]) > 0 {
+				continue
+			}
+			if err := r.createStep(ctx, workflow, ws, -1, ""); err != nil {
+				return
+			}
+			continue
+		}
+		if count, ok := workflow.Status.StepItemCounts[ws.Name]; ok && int32(len(groups[ws.Name])) >= count {
 			continue
 		}
-		if err := r.createStep(ctx, workflow, ws); err != nil {
+		satisfied, _ := r.dependOnsSatisfied(workflow, ws, groups)
+		items, ok, err := resolveItems(workflow, ws, satisfied)
+		if err != nil {
+			r.rollbackSpecWrong(ctx, workflow, steps, err)
 			return
 		}
+		if !ok {
+			continue
+		}
+		if workflow.Status.StepItemCounts == nil {
+			workflow.Status.StepItemCounts = map[string]int32{}
+		}
+		workflow.Status.StepItemCounts[ws.Name] = int32(len(items))
+		created := map[int]bool{}
+		for _, step := range groups[ws.Name] {
+			created[stepIndex(&step)] = true
+		}
+		for i, item := range items {
+			if created[i] {
+				continue
+			}
+			if err = r.createStep(ctx, workflow, ws, i, item); err != nil {
+				return
+			}
+		}
 	}
 }
 
-func (r *workflowReconciler) createStep(ctx context.Context, workflow *v1alpha1.Workflow, ws v1alpha1.WorkflowStep) error {
+// createStep 创建step，index 大于等于0 时为withItems/withParam 展开的第index 个step
+func (r *workflowReconciler) createStep(ctx context.Context, workflow *v1alpha1.Workflow, ws v1alpha1.WorkflowStep, index int, item string) error {
 	log := r.log.WithValues("name", workflow.Name)
 
 	step := &v1alpha1.Step{
@@ -39,11 +69,19 @@ func (r *workflowReconciler) createStep(ctx context.Context, workflow *v1alpha1.
 				"step":     ws.Name,
 			},
 		},
-		Spec: ws.StepTemplate,
+		Spec: *ws.StepTemplate.DeepCopy(),
 		Status: v1alpha1.StepStatus{
 			Phase: v1alpha1.StepPending,
 		},
 	}
+	if index >= 0 {
+		step.Name = fmt.Sprintf("%s-%s-%d", workflow.Name, ws.Name, index)
+		step.Labels[stepIndexLabel] = strconv.Itoa(index)
+		if step.Spec.Parameters == nil {
+			step.Spec.Parameters = map[string]string{}
+		}
+		step.Spec.Parameters[itemParameter] = item
+	}
 	// step RollbackPolicy 默认与workflow 保持一致
 	step.Spec.RollbackPolicy = workflow.Spec.RollbackPolicy
 
@@ -0,0 +1,41 @@
+package operators
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+// reconcileDeadline workflow 运行超过activeDeadlineSeconds 则回滚整个workflow，超时返回true
+func (r *workflowReconciler) reconcileDeadline(ctx context.Context, workflow *v1alpha1.Workflow, steps []v1alpha1.Step) bool {
+	if workflow.Status.StartedAt.IsZero() {
+		workflow.Status.StartedAt = metav1.Now()
+	}
+	left, ok := workflowDeadlineLeft(workflow)
+	if !ok || left > 0 {
+		return false
+	}
+	log := r.log.WithValues("name", workflow.Name)
+	currentPhase := workflow.Status.Phase
+	workflow.Status.Phase = v1alpha1.WorkflowRollingBack
+	workflow.Status.Reason = v1alpha1.DeadlineExceededReason
+	log.Info("workflow deadline exceeded, start rollback", "activeDeadlineSeconds", workflow.Spec.ActiveDeadlineSeconds)
+	r.recorder.Eventf(workflow, corev1.EventTypeNormal, v1alpha1.DeadlineExceededReason, "'%s' => '%s',running more than %d seconds",
+		currentPhase, workflow.Status.Phase, workflow.Spec.ActiveDeadlineSeconds)
+	// cancel 正在执行的step Run/Sync
+	r.controllerCtx.StepCanceler.Cancel(workflow.Namespace + workflow.Name)
+	r.reconcileRollingBack(ctx, workflow, steps)
+	return true
+}
+
+// workflowDeadlineLeft 距离workflow 超时的剩余时间，没有设置activeDeadlineSeconds 时返回false
+var workflowDeadlineLeft = func(workflow *v1alpha1.Workflow) (time.Duration, bool) {
+	if workflow.Spec.ActiveDeadlineSeconds <= 0 || workflow.Status.StartedAt.IsZero() {
+		return 0, false
+	}
+	return time.Until(workflow.Status.StartedAt.Add(time.Duration(workflow.Spec.ActiveDeadlineSeconds) * time.Second)), true
+}
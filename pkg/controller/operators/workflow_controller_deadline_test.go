@@ -0,0 +1,61 @@
+package operators
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/controller/manager"
+	"github.com/qiankunli/workflow/pkg/utils/cancel"
+)
+
+func TestReconcileDeadline(t *testing.T) {
+	a := v1alpha1.Step{}
+	a.Name = "example-a"
+	a.Labels = map[string]string{"step": "a"}
+	a.Status.Phase = v1alpha1.StepSuccess
+	steps := []v1alpha1.Step{a}
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	r := &workflowReconciler{
+		client:        fake.NewClientBuilder().WithScheme(scheme).WithObjects(&a).Build(),
+		log:           logr.Discard(),
+		recorder:      record.NewFakeRecorder(10),
+		controllerCtx: &manager.ControllerContext{StepCanceler: cancel.NewGroupCanceler()},
+	}
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{Steps: []v1alpha1.WorkflowStep{{Name: "a"}}}}
+	workflow.Name = "example"
+	workflow.Status.Phase = v1alpha1.WorkflowRunning
+	ctx := context.Background()
+	// 没有设置activeDeadlineSeconds 不会超时，开始时间会被记录
+	if r.reconcileDeadline(ctx, workflow, steps) || workflow.Status.StartedAt.IsZero() {
+		t.Fatalf("expect no deadline, got %+v", workflow.Status)
+	}
+	workflow.Spec.ActiveDeadlineSeconds = 10
+	if r.reconcileDeadline(ctx, workflow, steps) || workflow.Status.Phase != v1alpha1.WorkflowRunning {
+		t.Fatalf("expect workflow running before deadline, got %s", workflow.Status.Phase)
+	}
+
+	workflow.Status.StartedAt = metav1.NewTime(time.Now().Add(-time.Minute))
+	if !r.reconcileDeadline(ctx, workflow, steps) {
+		t.Fatalf("expect deadline exceeded")
+	}
+	if workflow.Status.Phase != v1alpha1.WorkflowRollingBack || workflow.Status.Reason != v1alpha1.DeadlineExceededReason {
+		t.Errorf("expect workflow rolling back, got %+v", workflow.Status)
+	}
+	actual := &v1alpha1.Step{}
+	if err := r.client.Get(ctx, client.ObjectKeyFromObject(&a), actual); err != nil {
+		t.Fatalf("get step error: %v", err)
+	}
+	if actual.Status.Phase != v1alpha1.StepRollingBack {
+		t.Errorf("expect step rolling back, got %s", actual.Status.Phase)
+	}
+}
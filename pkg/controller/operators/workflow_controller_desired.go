@@ -0,0 +1,42 @@
+package operators
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+// reconcileDesiredState spec.desiredState 为RollBacked 时回滚整个workflow，回滚完成后保持RollBacked，开始回滚时返回true
+func (r *workflowReconciler) reconcileDesiredState(ctx context.Context, workflow *v1alpha1.Workflow, steps []v1alpha1.Step) bool {
+	if !rollbackRequested(workflow) {
+		return false
+	}
+	log := r.log.WithValues("name", workflow.Name)
+	currentPhase := workflow.Status.Phase
+	switch currentPhase {
+	case "", v1alpha1.WorkflowPending:
+		// 还没有开始运行，没有需要回滚的step
+		workflow.Status.Phase = v1alpha1.WorkflowRollBacked
+	case v1alpha1.WorkflowRunning, v1alpha1.WorkflowSuccess:
+		workflow.Status.Phase = v1alpha1.WorkflowRollingBack
+	default:
+		return false
+	}
+	workflow.Status.Reason = v1alpha1.RollbackRequestedReason
+	log.Info("rollback requested by desiredState", "phase", currentPhase)
+	r.recorder.Eventf(workflow, corev1.EventTypeNormal, v1alpha1.RollbackRequestedReason, "'%s' => '%s',desiredState is %s",
+		currentPhase, workflow.Status.Phase, workflow.Spec.DesiredState)
+	if workflow.Status.Phase == v1alpha1.WorkflowRollingBack {
+		// cancel 正在执行的step Run/Sync
+		r.controllerCtx.StepCanceler.Cancel(workflow.Namespace + workflow.Name)
+		r.reconcileRollingBack(ctx, workflow, steps)
+	}
+	return true
+}
+
+// rollbackRequested spec.desiredState 要求回滚workflow
+var rollbackRequested = func(workflow *v1alpha1.Workflow) bool {
+	return workflow.Spec.DesiredState == v1alpha1.DesiredRollBacked
+}
@@ -0,0 +1,74 @@
+package operators
+
+import (
+	"context"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+func TestReconcileDesiredState(t *testing.T) {
+	a := newRunningTestStep("a", v1alpha1.StepSuccess)
+	b := newRunningTestStep("b", v1alpha1.StepSuccess)
+	steps := []v1alpha1.Step{a, b}
+	r := newRetryTestReconciler(steps...)
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{Steps: []v1alpha1.WorkflowStep{
+		{Name: "a"},
+		{Name: "b", DependOns: []v1alpha1.DependOn{{Name: "a", Phase: v1alpha1.StepSuccess}}},
+	}}}
+	workflow.Name = "example"
+	workflow.Status.Phase = v1alpha1.WorkflowSuccess
+	ctx := context.Background()
+	if r.reconcileDesiredState(ctx, workflow, steps) {
+		t.Fatalf("expect nothing to do without desiredState")
+	}
+
+	workflow.Spec.DesiredState = v1alpha1.DesiredRollBacked
+	if !r.reconcileDesiredState(ctx, workflow, steps) {
+		t.Fatalf("expect rollback started")
+	}
+	if workflow.Status.Phase != v1alpha1.WorkflowRollingBack || workflow.Status.Reason != v1alpha1.RollbackRequestedReason {
+		t.Errorf("expect workflow rolling back, got %+v", workflow.Status)
+	}
+	// 按依赖关系逆序回滚，a 要等b 回滚完成
+	expect := map[string]v1alpha1.StepPhase{"example-a": v1alpha1.StepSuccess, "example-b": v1alpha1.StepRollingBack}
+	for name, phase := range expect {
+		actual := &v1alpha1.Step{}
+		if err := r.client.Get(ctx, client.ObjectKey{Name: name}, actual); err != nil {
+			t.Fatalf("get step error: %v", err)
+		}
+		if actual.Status.Phase != phase {
+			t.Errorf("expect %s %s, got %s", name, phase, actual.Status.Phase)
+		}
+	}
+	// 已经在回滚中
+	if r.reconcileDesiredState(ctx, workflow, steps) {
+		t.Errorf("expect rollback not started twice")
+	}
+}
+
+func TestReconcileDesiredStatePending(t *testing.T) {
+	r := newRetryTestReconciler()
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{DesiredState: v1alpha1.DesiredRollBacked}}
+	workflow.Name = "example"
+	if !r.reconcileDesiredState(context.Background(), workflow, nil) || workflow.Status.Phase != v1alpha1.WorkflowRollBacked {
+		t.Errorf("expect pending workflow rollbacked, got %s", workflow.Status.Phase)
+	}
+}
+
+func TestAggregateStepStatusRollbackRequested(t *testing.T) {
+	r := newRetryTestReconciler()
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{
+		DesiredState: v1alpha1.DesiredRollBacked,
+		Steps:        []v1alpha1.WorkflowStep{{Name: "a"}},
+	}}
+	workflow.Name = "example"
+	workflow.Status.Phase = v1alpha1.WorkflowRollingBack
+	// 要求回滚的workflow 不再回到Success，step 都被跳过时直接RollBacked
+	r.aggregateStepStatus(context.Background(), workflow, []v1alpha1.Step{newRunningTestStep("a", v1alpha1.StepSkipped)})
+	if workflow.Status.Phase != v1alpha1.WorkflowRollBacked {
+		t.Errorf("expect workflow rollbacked, got %s", workflow.Status.Phase)
+	}
+}
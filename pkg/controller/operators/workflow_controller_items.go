@@ -0,0 +1,153 @@
+package operators
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+const (
+	// stepIndexLabel withItems/withParam 展开的step 在列表中的下标
+	stepIndexLabel = "step-index"
+	// itemParameter 展开的step parameters 中对应项的key
+	itemParameter = "item"
+)
+
+// isFanOut step 是否按withItems/withParam 展开为多个step
+func isFanOut(ws v1alpha1.WorkflowStep) bool {
+	return len(ws.WithItems) > 0 || len(ws.WithParam) > 0
+}
+
+// groupSteps 按WorkflowStep 对step 分组，展开的step 按下标排序
+func groupSteps(steps []v1alpha1.Step) map[string][]v1alpha1.Step {
+	groups := map[string][]v1alpha1.Step{}
+	for _, step := range steps {
+		stepName := step.Labels["step"]
+		groups[stepName] = append(groups[stepName], step)
+	}
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool { return stepIndex(&group[i]) < stepIndex(&group[j]) })
+	}
+	return groups
+}
+
+func stepIndex(step *v1alpha1.Step) int {
+	index, err := strconv.Atoi(step.Labels[stepIndexLabel])
+	if err != nil {
+		return -1
+	}
+	return index
+}
+
+// stepGroupPhase WorkflowStep 对应的一组step 的phase，还没有创建时为空，展开后为空列表时为Skipped
+func stepGroupPhase(workflow *v1alpha1.Workflow, stepName string, group []v1alpha1.Step) v1alpha1.StepPhase {
+	if len(group) == 0 {
+		if count, ok := workflow.Status.StepItemCounts[stepName]; ok && count == 0 {
+			return v1alpha1.StepSkipped
+		}
+		return ""
+	}
+	if len(group) == 1 {
+		return group[0].Status.Phase
+	}
+	count := map[v1alpha1.StepPhase]int{}
+	for _, step := range group {
+		count[step.Status.Phase]++
+	}
+	switch {
+	case count[v1alpha1.StepFailed] > 0:
+		return v1alpha1.StepFailed
+	case count[v1alpha1.StepRollingBack] > 0:
+		return v1alpha1.StepRollingBack
+	case count[v1alpha1.StepRollBacked] > 0 && count[v1alpha1.StepRollBacked]+count[v1alpha1.StepSkipped] == len(group):
+		return v1alpha1.StepRollBacked
+	case count[v1alpha1.StepRollBacked] > 0:
+		return v1alpha1.StepRollingBack
+	case count[v1alpha1.StepSkipped] == len(group):
+		return v1alpha1.StepSkipped
+	case count[v1alpha1.StepSuccess]+count[v1alpha1.StepSkipped] == len(group):
+		return v1alpha1.StepSuccess
+	case count[v1alpha1.StepRunning] > 0 || count[v1alpha1.StepSuccess] > 0 || count[v1alpha1.StepSkipped] > 0:
+		return v1alpha1.StepRunning
+	}
+	return v1alpha1.StepPending
+}
+
+// stepGroupPhases 每个WorkflowStep 对应的一组step 的phase
+func stepGroupPhases(workflow *v1alpha1.Workflow, steps []v1alpha1.Step) map[string]v1alpha1.StepPhase {
+	phases := map[string]v1alpha1.StepPhase{}
+	for stepName, group := range groupSteps(steps) {
+		phases[stepName] = stepGroupPhase(workflow, stepName, group)
+	}
+	return phases
+}
+
+// resolveItems 返回step 展开的列表，ok 为false 表示依赖的attributes 还没有就绪
+func resolveItems(workflow *v1alpha1.Workflow, ws v1alpha1.WorkflowStep, dependOnsSatisfied bool) (items []string, ok bool, err error) {
+	if len(ws.WithItems) > 0 {
+		return ws.WithItems, true, nil
+	}
+	if strings.HasPrefix(ws.WithParam, "attributes.") && !dependOnsSatisfied {
+		return nil, false, nil
+	}
+	value, found := workflowVariables(workflow)[ws.WithParam]
+	if !found {
+		return nil, false, fmt.Errorf("withParam %s of step %s not found", ws.WithParam, ws.Name)
+	}
+	items, err = parseItems(value)
+	if err != nil {
+		return nil, false, fmt.Errorf("withParam %s of step %s is not a JSON array: %v", ws.WithParam, ws.Name, err)
+	}
+	return items, true, nil
+}
+
+// parseItems 解析JSON 数组，字符串取其值，其它类型保留JSON
+func parseItems(value string) ([]string, error) {
+	raws := make([]json.RawMessage, 0)
+	if err := json.Unmarshal([]byte(value), &raws); err != nil {
+		return nil, err
+	}
+	items := make([]string, 0, len(raws))
+	for _, raw := range raws {
+		item := ""
+		if err := json.Unmarshal(raw, &item); err != nil {
+			item = string(raw)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// allStepsCreated WorkflowStep 对应的step 是否都已经创建，withParam 引用attributes 时依赖满足后才会展开
+func allStepsCreated(workflow *v1alpha1.Workflow, groups map[string][]v1alpha1.Step) bool {
+	for _, ws := range workflow.Spec.Steps {
+		expected := int32(1)
+		if isFanOut(ws) {
+			count, ok := workflow.Status.StepItemCounts[ws.Name]
+			if !ok {
+				return false
+			}
+			expected = count
+		}
+		if int32(len(groups[ws.Name])) < expected {
+			return false
+		}
+	}
+	return true
+}
+
+// stepCount workflow 已创建的step 数量，没有创建时为WorkflowStep 的数量
+var stepCount = func(workflow *v1alpha1.Workflow) int {
+	count := 0
+	for _, c := range workflow.Status.StepPhases {
+		count += c
+	}
+	if count == 0 {
+		return len(workflow.Spec.Steps)
+	}
+	return count
+}
@@ -0,0 +1,172 @@
+package operators
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+func newItemTestStep(name string, index int, phase v1alpha1.StepPhase) v1alpha1.Step {
+	step := newRunningTestStep(name, phase)
+	step.Name = step.Name + "-" + strconv.Itoa(index)
+	step.Labels[stepIndexLabel] = strconv.Itoa(index)
+	return step
+}
+
+func TestParseItems(t *testing.T) {
+	items, err := parseItems(`["us-east", 1, {"shard": 2}]`)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if expect := []string{"us-east", "1", `{"shard": 2}`}; !reflect.DeepEqual(items, expect) {
+		t.Errorf("expect %v, got %v", expect, items)
+	}
+	if _, err = parseItems(`us-east`); err == nil {
+		t.Errorf("expect error for non JSON array")
+	}
+}
+
+func TestResolveItems(t *testing.T) {
+	workflow := &v1alpha1.Workflow{
+		Spec:   v1alpha1.WorkflowSpec{Parameters: map[string]string{"regions": `["a","b"]`}},
+		Status: v1alpha1.WorkflowStatus{Attributes: map[string]string{"shards": `["1"]`}},
+	}
+	items, ok, err := resolveItems(workflow, v1alpha1.WorkflowStep{WithParam: "parameters.regions"}, false)
+	if err != nil || !ok || !reflect.DeepEqual(items, []string{"a", "b"}) {
+		t.Errorf("expect [a b], got %v %v %v", items, ok, err)
+	}
+	// 依赖没有满足时不展开attributes
+	if _, ok, err = resolveItems(workflow, v1alpha1.WorkflowStep{WithParam: "attributes.shards"}, false); ok || err != nil {
+		t.Errorf("expect not ready, got %v %v", ok, err)
+	}
+	items, ok, err = resolveItems(workflow, v1alpha1.WorkflowStep{WithParam: "attributes.shards"}, true)
+	if err != nil || !ok || !reflect.DeepEqual(items, []string{"1"}) {
+		t.Errorf("expect [1], got %v %v %v", items, ok, err)
+	}
+	if _, _, err = resolveItems(workflow, v1alpha1.WorkflowStep{WithParam: "attributes.missing"}, true); err == nil {
+		t.Errorf("expect error for missing attribute")
+	}
+}
+
+func TestStepGroupPhase(t *testing.T) {
+	workflow := &v1alpha1.Workflow{Status: v1alpha1.WorkflowStatus{StepItemCounts: map[string]int32{"empty": 0}}}
+	cases := []struct {
+		name   string
+		phases []v1alpha1.StepPhase
+		expect v1alpha1.StepPhase
+	}{
+		{name: "a", phases: nil, expect: ""},
+		{name: "empty", phases: nil, expect: v1alpha1.StepSkipped},
+		{name: "a", phases: []v1alpha1.StepPhase{v1alpha1.StepPending, v1alpha1.StepPending}, expect: v1alpha1.StepPending},
+		{name: "a", phases: []v1alpha1.StepPhase{v1alpha1.StepSuccess, v1alpha1.StepPending}, expect: v1alpha1.StepRunning},
+		{name: "a", phases: []v1alpha1.StepPhase{v1alpha1.StepSuccess, v1alpha1.StepSkipped}, expect: v1alpha1.StepSuccess},
+		{name: "a", phases: []v1alpha1.StepPhase{v1alpha1.StepSkipped, v1alpha1.StepSkipped}, expect: v1alpha1.StepSkipped},
+		{name: "a", phases: []v1alpha1.StepPhase{v1alpha1.StepSuccess, v1alpha1.StepRollingBack}, expect: v1alpha1.StepRollingBack},
+		{name: "a", phases: []v1alpha1.StepPhase{v1alpha1.StepSuccess, v1alpha1.StepRollBacked}, expect: v1alpha1.StepRollingBack},
+		{name: "a", phases: []v1alpha1.StepPhase{v1alpha1.StepSkipped, v1alpha1.StepRollBacked}, expect: v1alpha1.StepRollBacked},
+		{name: "a", phases: []v1alpha1.StepPhase{v1alpha1.StepFailed, v1alpha1.StepRollBacked}, expect: v1alpha1.StepFailed},
+	}
+	for _, c := range cases {
+		group := make([]v1alpha1.Step, 0)
+		for i, phase := range c.phases {
+			group = append(group, newItemTestStep(c.name, i, phase))
+		}
+		if actual := stepGroupPhase(workflow, c.name, group); actual != c.expect {
+			t.Errorf("%s %v: expect %s, got %s", c.name, c.phases, c.expect, actual)
+		}
+	}
+}
+
+func TestFindCanRunningStepGroup(t *testing.T) {
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{Steps: []v1alpha1.WorkflowStep{
+		{Name: "a", WithItems: []string{"x", "y"}},
+		{Name: "b", DependOns: []v1alpha1.DependOn{{Name: "a", Phase: v1alpha1.StepSuccess}}},
+	}}}
+	steps := []v1alpha1.Step{
+		newItemTestStep("a", 0, v1alpha1.StepSuccess),
+		newItemTestStep("a", 1, v1alpha1.StepRunning),
+		newRunningTestStep("b", v1alpha1.StepPending),
+	}
+	r := &workflowReconciler{}
+	for _, step := range r.findCanRunningStep(workflow, steps) {
+		if step.Labels["step"] == "b" {
+			t.Fatalf("expect b waits for the whole group of a")
+		}
+	}
+	steps[1].Status.Phase = v1alpha1.StepSuccess
+	found := false
+	for _, step := range r.findCanRunningStep(workflow, steps) {
+		found = found || step.Labels["step"] == "b"
+	}
+	if !found {
+		t.Errorf("expect b can run after all steps of a succeeded")
+	}
+}
+
+func TestFindRollingBackStepGroup(t *testing.T) {
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{Steps: []v1alpha1.WorkflowStep{
+		{Name: "a"},
+		{Name: "b", WithItems: []string{"x", "y"}, DependOns: []v1alpha1.DependOn{{Name: "a", Phase: v1alpha1.StepSuccess}}},
+	}}}
+	steps := []v1alpha1.Step{
+		newRunningTestStep("a", v1alpha1.StepSuccess),
+		newItemTestStep("b", 0, v1alpha1.StepRollBacked),
+		newItemTestStep("b", 1, v1alpha1.StepSuccess),
+	}
+	r := &workflowReconciler{}
+	got := map[string]bool{}
+	for _, step := range r.findRollingBackStep(workflow, steps) {
+		got[step.Name] = true
+	}
+	// a 要等b 展开的step 全部回滚
+	if got["example-a"] || !got["example-b-0"] || !got["example-b-1"] {
+		t.Errorf("expect only steps of b can rollback, got %v", got)
+	}
+}
+
+func TestReconcileCreatingItems(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	r := &workflowReconciler{
+		client:   fake.NewClientBuilder().WithScheme(scheme).Build(),
+		log:      logr.Discard(),
+		recorder: record.NewFakeRecorder(10),
+	}
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{Steps: []v1alpha1.WorkflowStep{
+		{Name: "a", WithItems: []string{"x", "y"}, StepTemplate: v1alpha1.StepSpec{Type: "empty"}},
+		{Name: "b", WithParam: "attributes.shards", DependOns: []v1alpha1.DependOn{{Name: "a", Phase: v1alpha1.StepSuccess}}},
+		{Name: "c"},
+	}}}
+	workflow.Name = "example"
+	workflow.Namespace = "default"
+	ctx := context.Background()
+	r.reconcileCreating(ctx, workflow, nil)
+
+	stepList := &v1alpha1.StepList{}
+	if err := r.client.List(ctx, stepList, client.InNamespace("default")); err != nil {
+		t.Fatalf("list steps error: %v", err)
+	}
+	items := map[string]string{}
+	for _, step := range stepList.Items {
+		items[step.Name] = step.Spec.Parameters[itemParameter]
+	}
+	// b 依赖a 的attributes，a 成功之前不展开
+	if expect := map[string]string{"example-a-0": "x", "example-a-1": "y", "example-c": ""}; !reflect.DeepEqual(items, expect) {
+		t.Errorf("expect %v, got %v", expect, items)
+	}
+	if count, ok := workflow.Status.StepItemCounts["a"]; !ok || count != 2 {
+		t.Errorf("expect 2 items of a, got %v", workflow.Status.StepItemCounts)
+	}
+	if allStepsCreated(workflow, groupSteps(stepList.Items)) {
+		t.Errorf("expect b not created")
+	}
+}
@@ -0,0 +1,116 @@
+package operators
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
+)
+
+// collectOutputs 按WorkflowStep 的顺序汇总step 的输出，返回stepOutputs 和导出的workflow attributes。
+// 没有声明outputs、exportAttributes 的step 导出全部attributes，和以前一样后面的step 覆盖前面的；exportAttributes 导出的值优先，
+// 多个step 通过exportAttributes 向同一个attribute 写入不同的值时保留先写入的值，并返回error
+func collectOutputs(workflow *v1alpha1.Workflow, steps []v1alpha1.Step) (map[string]v1alpha1.StepOutputs, map[string]string, error) {
+	groups := groupSteps(steps)
+	stepOutputs := map[string]v1alpha1.StepOutputs{}
+	attributes := map[string]string{}
+	// 通过exportAttributes 导出的attribute 及写入它的step
+	exported := map[string]string{}
+	writers := map[string]string{}
+	conflicts := make([]string, 0)
+	for _, ws := range workflow.Spec.Steps {
+		for i := range groups[ws.Name] {
+			step := &groups[ws.Name][i]
+			if outputs := outputsOf(ws, step); len(outputs) > 0 {
+				stepOutputs[outputsKey(ws, step)] = outputs
+			}
+			// withItems/withParam 展开的step 各自的值不同，不导出到workflow attributes
+			if isFanOut(ws) {
+				continue
+			}
+			if len(ws.Outputs) == 0 && len(ws.ExportAttributes) == 0 {
+				for k, v := range step.Status.Attributes {
+					attributes[k] = v
+				}
+				continue
+			}
+			for k, outputKey := range ws.ExportAttributes {
+				v, ok := step.Status.Attributes[outputKey]
+				if !ok {
+					continue
+				}
+				writer, ok := writers[k]
+				if !ok {
+					writers[k] = step.Name
+					exported[k] = v
+					continue
+				}
+				if exported[k] != v {
+					conflicts = append(conflicts, fmt.Sprintf("attribute %s is exported by both step %s and %s", k, writer, step.Name))
+				}
+			}
+		}
+	}
+	for k, v := range exported {
+		attributes[k] = v
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return stepOutputs, attributes, fmt.Errorf("%s", strings.Join(conflicts, "; "))
+	}
+	return stepOutputs, attributes, nil
+}
+
+// outputsKey withItems/withParam 展开的step 为 <step>-<index>
+func outputsKey(ws v1alpha1.WorkflowStep, step *v1alpha1.Step) string {
+	if isFanOut(ws) {
+		return fmt.Sprintf("%s-%d", ws.Name, stepIndex(step))
+	}
+	return ws.Name
+}
+
+// outputsOf step 声明的输出，没有声明时为全部attributes
+func outputsOf(ws v1alpha1.WorkflowStep, step *v1alpha1.Step) v1alpha1.StepOutputs {
+	outputs := v1alpha1.StepOutputs{}
+	if len(ws.Outputs) == 0 {
+		for k, v := range step.Status.Attributes {
+			outputs[k] = v
+		}
+		return outputs
+	}
+	for _, k := range ws.Outputs {
+		if v, ok := step.Status.Attributes[k]; ok {
+			outputs[k] = v
+		}
+	}
+	return outputs
+}
+
+// collectJSONOutputs 按WorkflowStep 的顺序汇总step 的status.outputs，key 与stepOutputs 相同，忽略不是JSON 的输出，没有输出时返回nil
+func collectJSONOutputs(workflow *v1alpha1.Workflow, steps []v1alpha1.Step) *runtime.RawExtension {
+	groups := groupSteps(steps)
+	var outputs []byte
+	for _, ws := range workflow.Spec.Steps {
+		for i := range groups[ws.Name] {
+			step := &groups[ws.Name][i]
+			if step.Status.Outputs == nil || !gjson.ValidBytes(step.Status.Outputs.Raw) {
+				continue
+			}
+			if outputs == nil {
+				outputs = []byte("{}")
+			}
+			key := stepinterface.EscapePath(outputsKey(ws, step))
+			outputs, _ = sjson.SetRawBytes(outputs, key, step.Status.Outputs.Raw)
+		}
+	}
+	if outputs == nil {
+		return nil
+	}
+	return &runtime.RawExtension{Raw: outputs}
+}
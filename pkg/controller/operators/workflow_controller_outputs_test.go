@@ -0,0 +1,109 @@
+package operators
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+func TestCollectOutputs(t *testing.T) {
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{Steps: []v1alpha1.WorkflowStep{
+		{Name: "a"},
+		{Name: "b", Outputs: []string{"vpcId"}, ExportAttributes: map[string]string{"vpc": "vpcId"}},
+		{Name: "c", WithItems: []string{"x", "y"}, Outputs: []string{"id"}},
+	}}}
+	a := newRunningTestStep("a", v1alpha1.StepSuccess)
+	a.Status.Attributes = map[string]string{"ip": "10.0.0.1"}
+	b := newRunningTestStep("b", v1alpha1.StepSuccess)
+	b.Status.Attributes = map[string]string{"vpcId": "vpc-1", "internal": "x"}
+	c0 := newItemTestStep("c", 0, v1alpha1.StepSuccess)
+	c0.Status.Attributes = map[string]string{"id": "c-0"}
+	c1 := newItemTestStep("c", 1, v1alpha1.StepSuccess)
+	c1.Status.Attributes = map[string]string{"id": "c-1"}
+
+	stepOutputs, attributes, err := collectOutputs(workflow, []v1alpha1.Step{c1, b, a, c0})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	expectOutputs := map[string]v1alpha1.StepOutputs{
+		"a":   {"ip": "10.0.0.1"},
+		"b":   {"vpcId": "vpc-1"},
+		"c-0": {"id": "c-0"},
+		"c-1": {"id": "c-1"},
+	}
+	if !reflect.DeepEqual(stepOutputs, expectOutputs) {
+		t.Errorf("expect step outputs %v, got %v", expectOutputs, stepOutputs)
+	}
+	// a 没有声明outputs，导出全部attributes；展开的step 不导出
+	if expect := map[string]string{"ip": "10.0.0.1", "vpc": "vpc-1"}; !reflect.DeepEqual(attributes, expect) {
+		t.Errorf("expect attributes %v, got %v", expect, attributes)
+	}
+}
+
+func TestCollectOutputsConflict(t *testing.T) {
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{Steps: []v1alpha1.WorkflowStep{
+		{Name: "a", ExportAttributes: map[string]string{"ip": "ip"}},
+		{Name: "b", ExportAttributes: map[string]string{"ip": "ip", "zone": "zone"}},
+	}}}
+	a := newRunningTestStep("a", v1alpha1.StepSuccess)
+	a.Status.Attributes = map[string]string{"ip": "10.0.0.1", "zone": "1a"}
+	b := newRunningTestStep("b", v1alpha1.StepSuccess)
+	b.Status.Attributes = map[string]string{"ip": "10.0.0.2", "zone": "1a"}
+
+	_, attributes, err := collectOutputs(workflow, []v1alpha1.Step{b, a})
+	if err == nil || err.Error() != "attribute ip is exported by both step example-a and example-b" {
+		t.Errorf("expect conflict error, got %v", err)
+	}
+	// 与List 的顺序无关，保留先声明的step 写入的值
+	if attributes["ip"] != "10.0.0.1" {
+		t.Errorf("expect attribute of a kept, got %v", attributes)
+	}
+}
+
+func TestCollectOutputsLegacyMerge(t *testing.T) {
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{Steps: []v1alpha1.WorkflowStep{
+		{Name: "a"},
+		{Name: "b"},
+		{Name: "c", ExportAttributes: map[string]string{"zone": "zone"}},
+	}}}
+	a := newRunningTestStep("a", v1alpha1.StepSuccess)
+	a.Status.Attributes = map[string]string{"ip": "10.0.0.1", "zone": "1a"}
+	b := newRunningTestStep("b", v1alpha1.StepSuccess)
+	b.Status.Attributes = map[string]string{"ip": "10.0.0.2", "zone": "1b"}
+	c := newRunningTestStep("c", v1alpha1.StepSuccess)
+	c.Status.Attributes = map[string]string{"zone": "1c"}
+
+	// 没有声明outputs、exportAttributes 的step 后面的覆盖前面的，exportAttributes 导出的值优先，都不算冲突
+	_, attributes, err := collectOutputs(workflow, []v1alpha1.Step{c, b, a})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if expect := map[string]string{"ip": "10.0.0.2", "zone": "1c"}; !reflect.DeepEqual(attributes, expect) {
+		t.Errorf("expect attributes %v, got %v", expect, attributes)
+	}
+}
+
+func TestCollectJSONOutputs(t *testing.T) {
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{Steps: []v1alpha1.WorkflowStep{
+		{Name: "a"},
+		{Name: "b.v2"},
+		{Name: "c", WithItems: []string{"x"}},
+	}}}
+	if outputs := collectJSONOutputs(workflow, nil); outputs != nil {
+		t.Errorf("expect nil outputs, got %s", outputs.Raw)
+	}
+	a := newRunningTestStep("a", v1alpha1.StepSuccess)
+	a.Status.Outputs = &runtime.RawExtension{Raw: []byte(`{"subnets":[{"id":"subnet-1"}]}`)}
+	b := newRunningTestStep("b.v2", v1alpha1.StepSuccess)
+	b.Status.Outputs = &runtime.RawExtension{Raw: []byte(`{"ok":true}`)}
+	c0 := newItemTestStep("c", 0, v1alpha1.StepSuccess)
+	c0.Status.Outputs = &runtime.RawExtension{Raw: []byte(`not json`)}
+	outputs := collectJSONOutputs(workflow, []v1alpha1.Step{c0, b, a})
+	// step 名称中的点被转义，不是JSON 的输出被忽略
+	if expect := `{"a":{"subnets":[{"id":"subnet-1"}]},"b.v2":{"ok":true}}`; outputs == nil || string(outputs.Raw) != expect {
+		t.Errorf("expect %s, got %v", expect, outputs)
+	}
+}
@@ -0,0 +1,113 @@
+package operators
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/constants"
+	"github.com/qiankunli/workflow/pkg/utils/kube"
+)
+
+// reconcileRetry 处理Failed workflow 的retry annotation，Failed 的step 重置后继续运行或继续回滚，有step 重试时返回true
+func (r *workflowReconciler) reconcileRetry(ctx context.Context, workflow *v1alpha1.Workflow, steps []v1alpha1.Step) bool {
+	if _, ok := workflow.Annotations[constants.AnnotationRetry]; !ok {
+		return false
+	}
+	log := r.log.WithValues("name", workflow.Name)
+	// annotation 只生效一次，无论是否重试都要删掉
+	delete(workflow.Annotations, constants.AnnotationRetry)
+	if workflow.Status.Phase != v1alpha1.WorkflowFailed {
+		log.Info("ignore retry of workflow not failed", "phase", workflow.Status.Phase)
+		r.recorder.Eventf(workflow, corev1.EventTypeWarning, v1alpha1.RetriedReason, "ignore retry, workflow is %s", workflow.Status.Phase)
+		return false
+	}
+	nextPhase := retryPhase(workflow, steps)
+	retried := 0
+	for _, step := range steps {
+		if step.Status.Phase != v1alpha1.StepFailed {
+			continue
+		}
+		curStep := step
+		base := step.DeepCopy()
+		err := kube.RetryUpdateStatusOnConflict(ctx, r.client, base, func() error {
+			resetFailedStep(base, nextPhase)
+			return nil
+		})
+		if client.IgnoreNotFound(err) != nil {
+			log.Error(err, "update step error", "name", base.Name)
+			continue
+		}
+		retried++
+		r.recorder.Eventf(&curStep, corev1.EventTypeNormal, v1alpha1.RetriedReason, "'%s' => '%s'", v1alpha1.StepFailed, nextPhase)
+	}
+	if retried == 0 {
+		return false
+	}
+	currentPhase := workflow.Status.Phase
+	workflow.Status.RunError = ""
+	workflow.Status.RollbackError = ""
+	workflow.Status.Reason = v1alpha1.RetriedReason
+	if nextPhase == v1alpha1.StepRunning {
+		workflow.Status.Phase = v1alpha1.WorkflowRunning
+		// 重新计算activeDeadlineSeconds
+		workflow.Status.StartedAt = metav1.Now()
+	} else {
+		workflow.Status.Phase = v1alpha1.WorkflowRollingBack
+	}
+	log.Info("retry failed steps", "count", retried, "phase", workflow.Status.Phase)
+	r.recorder.Eventf(workflow, corev1.EventTypeNormal, v1alpha1.RetriedReason, "'%s' => '%s',retry %d failed steps",
+		currentPhase, workflow.Status.Phase, retried)
+	return true
+}
+
+// retryPhase 只有workflow 没有开始回滚时才继续运行，否则继续回滚
+func retryPhase(workflow *v1alpha1.Workflow, steps []v1alpha1.Step) v1alpha1.StepPhase {
+	if !workflow.DeletionTimestamp.IsZero() || rollbackRequested(workflow) {
+		return v1alpha1.StepRollingBack
+	}
+	for _, step := range steps {
+		switch step.Status.Phase {
+		case v1alpha1.StepRollingBack, v1alpha1.StepRollBacked:
+			return v1alpha1.StepRollingBack
+		case v1alpha1.StepFailed:
+			// 回滚失败的step
+			if len(step.Status.RollbackError) > 0 || !step.Status.LatestRollbackRetryAt.IsZero() {
+				return v1alpha1.StepRollingBack
+			}
+		}
+	}
+	return v1alpha1.StepRunning
+}
+
+// resetFailedStep 把本次尝试记录到attempts，清理重试次数和错误后进入phase
+func resetFailedStep(step *v1alpha1.Step, phase v1alpha1.StepPhase) {
+	status := &step.Status
+	status.Attempts = append(status.Attempts, v1alpha1.StepAttempt{
+		RunRetryCount:      status.RunRetryCount,
+		RollbackRetryCount: status.RollbackRetryCount,
+		RunError:           status.RunError,
+		RollbackError:      status.RollbackError,
+		Reason:             status.Reason,
+		StartedAt:          status.StartedAt,
+		RetriedAt:          metav1.Now(),
+	})
+	status.Phase = phase
+	status.Reason = v1alpha1.RetriedReason
+	status.RunRetryCount = 0
+	status.RollbackRetryCount = 0
+	status.RunError = ""
+	status.RollbackError = ""
+	status.RetryCodeCounts = nil
+	status.LatestRunRetryAt = metav1.Time{}
+	status.NextRunRetryAt = metav1.Time{}
+	status.LatestRollbackRetryAt = metav1.Time{}
+	status.NextRollbackRetryAt = metav1.Time{}
+	status.SubmittedAt = metav1.Time{}
+	status.LatestPollAt = metav1.Time{}
+	// 重新计算timeoutSeconds
+	status.StartedAt = metav1.Time{}
+}
@@ -0,0 +1,219 @@
+package operators
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/constants"
+	"github.com/qiankunli/workflow/pkg/controller/manager"
+	"github.com/qiankunli/workflow/pkg/options"
+	"github.com/qiankunli/workflow/pkg/utils/cancel"
+	"github.com/qiankunli/workflow/pkg/utils/mutex"
+)
+
+func newRetryTestReconciler(steps ...v1alpha1.Step) *workflowReconciler {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for i := range steps {
+		builder = builder.WithObjects(&steps[i])
+	}
+	return &workflowReconciler{
+		client:        builder.Build(),
+		log:           logr.Discard(),
+		recorder:      record.NewFakeRecorder(10),
+		WorkflowMutex: mutex.NewGroupMutex(),
+		controllerCtx: &manager.ControllerContext{
+			Config:        options.NewDefaultConfig(),
+			StepCanceler:  cancel.NewGroupCanceler(),
+			WorkflowMutex: mutex.NewGroupMutex(),
+		},
+	}
+}
+
+func newRetryTestWorkflow(phase v1alpha1.WorkflowPhase) *v1alpha1.Workflow {
+	workflow := &v1alpha1.Workflow{}
+	workflow.Name = "example"
+	workflow.Annotations = map[string]string{constants.AnnotationRetry: "true"}
+	workflow.Status.Phase = phase
+	workflow.Status.RunError = "empty:boom"
+	return workflow
+}
+
+func TestReconcileRetryRunning(t *testing.T) {
+	a := newRunningTestStep("a", v1alpha1.StepSuccess)
+	b := newRunningTestStep("b", v1alpha1.StepFailed)
+	b.Status.RunRetryCount = 3
+	b.Status.RunError = "boom"
+	steps := []v1alpha1.Step{a, b}
+	r := newRetryTestReconciler(steps...)
+	workflow := newRetryTestWorkflow(v1alpha1.WorkflowFailed)
+	ctx := context.Background()
+	if !r.reconcileRetry(ctx, workflow, steps) {
+		t.Fatalf("expect failed steps retried")
+	}
+	if _, ok := workflow.Annotations[constants.AnnotationRetry]; ok {
+		t.Errorf("expect retry annotation removed")
+	}
+	if workflow.Status.Phase != v1alpha1.WorkflowRunning || len(workflow.Status.RunError) > 0 {
+		t.Errorf("expect workflow running without error, got %+v", workflow.Status)
+	}
+	actual := &v1alpha1.Step{}
+	if err := r.client.Get(ctx, client.ObjectKeyFromObject(&b), actual); err != nil {
+		t.Fatalf("get step error: %v", err)
+	}
+	if actual.Status.Phase != v1alpha1.StepRunning || actual.Status.RunRetryCount != 0 || len(actual.Status.RunError) > 0 {
+		t.Errorf("expect step reset to running, got %+v", actual.Status)
+	}
+	// 保留之前的尝试
+	if len(actual.Status.Attempts) != 1 || actual.Status.Attempts[0].RunRetryCount != 3 || actual.Status.Attempts[0].RunError != "boom" {
+		t.Errorf("expect previous attempt kept, got %+v", actual.Status.Attempts)
+	}
+	if err := r.client.Get(ctx, client.ObjectKeyFromObject(&a), actual); err != nil {
+		t.Fatalf("get step error: %v", err)
+	}
+	if actual.Status.Phase != v1alpha1.StepSuccess || len(actual.Status.Attempts) > 0 {
+		t.Errorf("expect success step untouched, got %+v", actual.Status)
+	}
+}
+
+func TestReconcileRetryRollingBack(t *testing.T) {
+	a := newRunningTestStep("a", v1alpha1.StepFailed)
+	a.Status.RollbackRetryCount = 3
+	a.Status.RollbackError = "boom"
+	b := newRunningTestStep("b", v1alpha1.StepRollBacked)
+	steps := []v1alpha1.Step{a, b}
+	r := newRetryTestReconciler(steps...)
+	workflow := newRetryTestWorkflow(v1alpha1.WorkflowFailed)
+	ctx := context.Background()
+	if !r.reconcileRetry(ctx, workflow, steps) {
+		t.Fatalf("expect failed steps retried")
+	}
+	if workflow.Status.Phase != v1alpha1.WorkflowRollingBack {
+		t.Errorf("expect workflow rolling back, got %s", workflow.Status.Phase)
+	}
+	actual := &v1alpha1.Step{}
+	if err := r.client.Get(ctx, client.ObjectKeyFromObject(&a), actual); err != nil {
+		t.Fatalf("get step error: %v", err)
+	}
+	if actual.Status.Phase != v1alpha1.StepRollingBack || actual.Status.RollbackRetryCount != 0 || len(actual.Status.RollbackError) > 0 {
+		t.Errorf("expect step reset to rolling back, got %+v", actual.Status)
+	}
+}
+
+func TestReconcileRetryNotFailed(t *testing.T) {
+	steps := []v1alpha1.Step{newRunningTestStep("a", v1alpha1.StepRunning)}
+	r := newRetryTestReconciler(steps...)
+	workflow := newRetryTestWorkflow(v1alpha1.WorkflowRunning)
+	if r.reconcileRetry(context.Background(), workflow, steps) {
+		t.Errorf("expect no retry for running workflow")
+	}
+	if _, ok := workflow.Annotations[constants.AnnotationRetry]; ok {
+		t.Errorf("expect retry annotation removed")
+	}
+}
+
+// newRetryForwardTest 运行时失败的workflow example，step a 和b 互不依赖
+func newRetryForwardTest(t *testing.T, callback string, steps ...v1alpha1.Step) (*workflowReconciler, ctrl.Request) {
+	workflowSteps := make([]v1alpha1.WorkflowStep, 0, len(steps))
+	for i := range steps {
+		steps[i].Namespace = "default"
+		steps[i].Labels["workflow"] = "example"
+		workflowSteps = append(workflowSteps, v1alpha1.WorkflowStep{Name: steps[i].Labels["step"]})
+	}
+	r := newRetryTestReconciler(steps...)
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{
+		Callback: v1alpha1.Callback{Url: callback},
+		Steps:    workflowSteps,
+	}}
+	workflow.Name = "example"
+	workflow.Namespace = "default"
+	workflow.Status.Phase = v1alpha1.WorkflowFailed
+	if err := r.client.Create(context.Background(), workflow); err != nil {
+		t.Fatalf("create workflow error: %v", err)
+	}
+	return r, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(workflow)}
+}
+
+// retryTestWorkflow 设置retry annotation 后reconcile，返回最新的workflow
+func retryTestWorkflow(t *testing.T, r *workflowReconciler, req ctrl.Request) *v1alpha1.Workflow {
+	ctx := context.Background()
+	workflow := &v1alpha1.Workflow{}
+	if err := r.client.Get(ctx, req.NamespacedName, workflow); err != nil {
+		t.Fatalf("get workflow error: %v", err)
+	}
+	metav1.SetMetaDataAnnotation(&workflow.ObjectMeta, constants.AnnotationRetry, "true")
+	if err := r.client.Update(ctx, workflow); err != nil {
+		t.Fatalf("update workflow error: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile error: %v", err)
+	}
+	if err := r.client.Get(ctx, req.NamespacedName, workflow); err != nil {
+		t.Fatalf("get workflow error: %v", err)
+	}
+	return workflow
+}
+
+// getRetryTestStep 返回namespace default 中最新的step
+func getRetryTestStep(t *testing.T, r *workflowReconciler, step *v1alpha1.Step) *v1alpha1.Step {
+	actual := &v1alpha1.Step{}
+	if err := r.client.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: step.Name}, actual); err != nil {
+		t.Fatalf("get step error: %v", err)
+	}
+	return actual
+}
+
+func TestReconcileRetryForward(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	defer server.Close()
+	b := newRunningTestStep("b", v1alpha1.StepFailed)
+	b.Status.RunError = "step type not found"
+	r, req := newRetryForwardTest(t, server.URL, b)
+	// 没有需要回滚的step，重试后继续运行
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("reconcile error: %v", err)
+	}
+	workflow := retryTestWorkflow(t, r, req)
+	if workflow.Status.Phase != v1alpha1.WorkflowRunning || workflow.Annotations[constants.AnnotationRetry] != "" {
+		t.Errorf("expect workflow running and annotation removed, got %s %v", workflow.Status.Phase, workflow.Annotations)
+	}
+	if actual := getRetryTestStep(t, r, &b); actual.Status.Phase != v1alpha1.StepRunning || len(actual.Status.Attempts) != 1 {
+		t.Errorf("expect step b running again, got %+v", actual.Status)
+	}
+}
+
+func TestReconcileRetryAfterAutoRollback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	defer server.Close()
+	a := newRunningTestStep("a", v1alpha1.StepSuccess)
+	b := newRunningTestStep("b", v1alpha1.StepFailed)
+	b.Status.RunError = "step type not found"
+	r, req := newRetryForwardTest(t, server.URL, a, b)
+	// Failed 的workflow 自动回滚成功的step
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("reconcile error: %v", err)
+	}
+	if phase := getRetryTestStep(t, r, &a).Status.Phase; phase != v1alpha1.StepRollingBack {
+		t.Fatalf("expect step a rolling back, got %s", phase)
+	}
+	// 已经开始回滚，重试后继续回滚
+	workflow := retryTestWorkflow(t, r, req)
+	if workflow.Status.Phase != v1alpha1.WorkflowRollingBack {
+		t.Errorf("expect workflow rolling back, got %s", workflow.Status.Phase)
+	}
+	if actual := getRetryTestStep(t, r, &b); actual.Status.Phase != v1alpha1.StepRollingBack || len(actual.Status.Attempts) != 1 {
+		t.Errorf("expect step b rolling back, got %+v", actual.Status)
+	}
+}
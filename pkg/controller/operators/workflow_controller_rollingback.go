@@ -48,11 +48,8 @@ func (r *workflowReconciler) reconcileRollingBack(ctx context.Context, workflow
 }
 
 func (r *workflowReconciler) findRollingBackStep(workflow *v1alpha1.Workflow, steps []v1alpha1.Step) []v1alpha1.Step {
-	stepMap := map[string]v1alpha1.Step{}
-	for _, step := range steps {
-		stepName := step.Labels["step"]
-		stepMap[stepName] = step
-	}
+	// withItems/withParam 展开的step 按WorkflowStep 分组
+	groups := groupSteps(steps)
 	// 建立反向依赖关系 <stepName,reverseDependOnStep>
 	reverseDependOnMap := map[string][]string{}
 	for _, workflowStep := range workflow.Spec.Steps {
@@ -72,21 +69,42 @@ func (r *workflowReconciler) findRollingBackStep(workflow *v1alpha1.Workflow, st
 		reverseDependOnSteps := reverseDependOnMap[stepName]
 		// 没有反向依赖
 		if len(reverseDependOnSteps) == 0 {
-			ret = append(ret, stepMap[stepName])
+			ret = append(ret, step)
 			continue
 		}
-		count := 0
+		canRollback := true
 		for _, reverseDependOn := range reverseDependOnSteps {
-			reverseDependOnStep, ok := stepMap[reverseDependOn]
-			// 反向依赖step已不存在或已回滚
-			if !ok || seeAsRollBackedStep(&reverseDependOnStep) {
-				count++
+			// 反向依赖step已不存在、已回滚、被跳过或者还没有运行，依赖有环时环上的step 都不会运行；
+			// dependMode 为Any/Expression 时，反向依赖step 运行时没有用到本step，也不需要等待；
+			// 反向依赖step 展开为多个step 时，要等全部回滚完成
+			for _, reverseDependOnStep := range groups[reverseDependOn] {
+				if !seeAsRollBackedStep(&reverseDependOnStep) && !isNotStartedStep(&reverseDependOnStep) &&
+					reliesOn(&reverseDependOnStep, stepName) {
+					canRollback = false
+				}
 			}
 		}
 		// 反向依赖step全部 StepRollBacked 或不存在 则本任务可以rollback
-		if len(reverseDependOnSteps) == count {
-			ret = append(ret, stepMap[stepName])
+		if canRollback {
+			ret = append(ret, step)
 		}
 	}
 	return ret
 }
+
+var isNotStartedStep = func(step *v1alpha1.Step) bool {
+	return step.Status.Phase == "" || step.Status.Phase == v1alpha1.StepPending
+}
+
+// reliesOn step 运行时是否用到了dependOn，没有记录时认为用到了
+var reliesOn = func(step *v1alpha1.Step, dependOn string) bool {
+	if len(step.Status.DependOns) == 0 {
+		return true
+	}
+	for _, name := range step.Status.DependOns {
+		if name == dependOn {
+			return true
+		}
+	}
+	return false
+}
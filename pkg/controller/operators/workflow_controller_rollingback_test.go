@@ -0,0 +1,34 @@
+package operators
+
+import (
+	"testing"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+func TestFindRollingBackStepAny(t *testing.T) {
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{Steps: []v1alpha1.WorkflowStep{
+		{Name: "a"},
+		{Name: "b"},
+		{Name: "c", DependMode: v1alpha1.DependAny, DependOns: []v1alpha1.DependOn{
+			{Name: "a", Phase: v1alpha1.StepSuccess}, {Name: "b", Phase: v1alpha1.StepSuccess},
+		}},
+	}}}
+	c := newRunningTestStep("c", v1alpha1.StepSuccess)
+	// c 运行时只用到了b
+	c.Status.DependOns = []string{"b"}
+	steps := []v1alpha1.Step{
+		newRunningTestStep("a", v1alpha1.StepRunning),
+		newRunningTestStep("b", v1alpha1.StepSuccess),
+		c,
+	}
+	r := &workflowReconciler{}
+	got := map[string]bool{}
+	for _, step := range r.findRollingBackStep(workflow, steps) {
+		got[step.Labels["step"]] = true
+	}
+	// b 要等c 回滚完成，a 不需要
+	if !got["a"] || got["b"] || !got["c"] {
+		t.Errorf("expect a and c can rollback, got %v", got)
+	}
+}
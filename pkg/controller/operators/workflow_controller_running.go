@@ -2,8 +2,13 @@ package operators
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
+	"github.com/qiankunli/workflow/pkg/dag"
+	"github.com/qiankunli/workflow/pkg/expression"
 	"github.com/qiankunli/workflow/pkg/utils"
 	"github.com/qiankunli/workflow/pkg/utils/kube"
 	corev1 "k8s.io/api/core/v1"
@@ -12,64 +17,273 @@ import (
 
 func (r *workflowReconciler) reconcileRunning(ctx context.Context, workflow *v1alpha1.Workflow, steps []v1alpha1.Step) {
 	log := r.log.WithValues("name", workflow.Name)
+	// 暂停时Pending 的step 保持不动，恢复后继续
+	if workflow.Spec.Suspend {
+		log.V(4).Info("workflow is suspended, skip starting steps")
+		return
+	}
+	r.skipUnsatisfiable(ctx, workflow, steps)
+	failed := hasFailedStep(steps)
 	// 有step 成功，则触发下一个
 	canRunningSteps := r.findCanRunningStep(workflow, steps)
 	log.V(4).Info("find canRollingBackSteps", "count", len(canRunningSteps))
+	workflowSteps := map[string]v1alpha1.WorkflowStep{}
+	for _, workflowStep := range workflow.Spec.Steps {
+		workflowSteps[workflowStep.Name] = workflowStep
+	}
+	groups := groupSteps(steps)
+	// withItems/withParam 展开的step 正在运行的数量
+	runningCount := map[string]int32{}
+	for _, step := range steps {
+		if step.Status.Phase == v1alpha1.StepRunning {
+			runningCount[step.Labels["step"]]++
+		}
+	}
 	for _, step := range canRunningSteps {
 		curStep := step
 		currentStepPhase := step.Status.Phase
 		if currentStepPhase == "" || currentStepPhase == v1alpha1.StepPending {
-			log.V(4).Info("change step running", "name", step.Name)
+			stepName := step.Labels["step"]
+			workflowStep := workflowSteps[stepName]
+			// 有step 失败后只运行处理失败的step
+			if failed && !dag.HandlesFailure(workflowStep) {
+				continue
+			}
+			nextPhase := v1alpha1.StepRunning
+			message := ""
+			if when := workflowStep.When; len(when) > 0 {
+				ok, err := expression.EvaluateBool(when, stepVariables(workflow, &step, groups))
+				if err != nil {
+					// when 无法计算，step 永远无法运行，回滚workflow
+					r.rollbackSpecWrong(ctx, workflow, steps, fmt.Errorf("evaluate when of step %s error: %v", stepName, err))
+					return
+				}
+				if !ok {
+					nextPhase = v1alpha1.StepSkipped
+					message = fmt.Sprintf(",when '%s' is false", when)
+				}
+			}
+			if nextPhase == v1alpha1.StepRunning && workflowStep.Parallelism > 0 && runningCount[stepName] >= workflowStep.Parallelism {
+				log.V(4).Info("step reach parallelism", "name", step.Name, "parallelism", workflowStep.Parallelism)
+				continue
+			}
+			var resolvedParameters map[string]string
+			if nextPhase == v1alpha1.StepRunning {
+				var err error
+				if resolvedParameters, err = resolveStepParameters(workflow, &step, groups); err != nil {
+					// 引用的变量不存在，step 永远无法运行，回滚workflow
+					r.rollbackSpecWrong(ctx, workflow, steps, fmt.Errorf("resolve parameters of step %s error: %v", stepName, err))
+					return
+				}
+			}
+			log.V(4).Info("change step phase", "name", step.Name, "phase", nextPhase)
 			base := step.DeepCopy()
 			err := kube.RetryUpdateStatusOnConflict(ctx, r.client, base, func() error {
-				base.Status.Phase = v1alpha1.StepRunning
+				base.Status.Phase = nextPhase
+				base.Status.DependOns = curStep.Status.DependOns
+				base.Status.ResolvedParameters = resolvedParameters
 				return nil
 			})
 			if client.IgnoreNotFound(err) != nil {
 				log.Error(err, "update step error", "name", base.Name)
 			} else {
-				r.recorder.Eventf(&curStep, corev1.EventTypeNormal, v1alpha1.PhaseChangeReason, "'%s' => '%s'",
-					utils.FirstNotNull(currentStepPhase, v1alpha1.StepPending), v1alpha1.StepRunning)
+				if nextPhase == v1alpha1.StepRunning {
+					runningCount[stepName]++
+				}
+				r.recorder.Eventf(&curStep, corev1.EventTypeNormal, v1alpha1.PhaseChangeReason, "'%s' => '%s'%s",
+					utils.FirstNotNull(currentStepPhase, v1alpha1.StepPending), nextPhase, message)
 			}
 		}
 	}
 }
 
-func (r *workflowReconciler) findCanRunningStep(workflow *v1alpha1.Workflow, steps []v1alpha1.Step) []v1alpha1.Step {
-	stepMap := map[string]v1alpha1.Step{}
-	for _, step := range steps {
-		stepName := step.Labels["step"]
-		stepMap[stepName] = step
+// skipUnsatisfiable 处理失败的step 依赖永远无法满足时跳过，比如依赖的step 已经成功
+func (r *workflowReconciler) skipUnsatisfiable(ctx context.Context, workflow *v1alpha1.Workflow, steps []v1alpha1.Step) {
+	log := r.log.WithValues("name", workflow.Name)
+	skipped := map[string]bool{}
+	for _, u := range dag.FindUnsatisfiable(workflow.Spec.Steps, stepGroupPhases(workflow, steps)) {
+		if u.Skipped {
+			skipped[u.Step] = true
+		}
 	}
-	ret := make([]v1alpha1.Step, 0)
+	groups := groupSteps(steps)
 	for _, workflowStep := range workflow.Spec.Steps {
-		// 判断 当前 workflowStep 是否可以running
-		// 没有依赖，可以执行
-		if len(workflowStep.DependOns) == 0 {
-			ret = append(ret, stepMap[workflowStep.Name])
+		if !skipped[workflowStep.Name] {
 			continue
 		}
-		dependOnCount := 0
-		for _, dependOn := range workflowStep.DependOns {
-			dependOnStep := stepMap[dependOn.Name]
-			dependOnStepPhase := dependOnStep.Status.Phase
-			// 依赖step 的phase 不对
-			if dependOn.Phase != dependOnStepPhase {
+		for _, step := range groups[workflowStep.Name] {
+			currentStepPhase := step.Status.Phase
+			if currentStepPhase != "" && currentStepPhase != v1alpha1.StepPending {
 				continue
 			}
-			// 依赖step 的ResourceStatus 不对，如果有的话
-			if len(dependOn.ResourceStatus) > 0 {
-				dependOnStepResourceStatus := dependOnStep.Status.Resource.Status
-				if dependOn.ResourceStatus != dependOnStepResourceStatus {
-					continue
-				}
+			base := step.DeepCopy()
+			err := kube.RetryUpdateStatusOnConflict(ctx, r.client, base, func() error {
+				base.Status.Phase = v1alpha1.StepSkipped
+				return nil
+			})
+			if client.IgnoreNotFound(err) != nil {
+				log.Error(err, "update step error", "name", base.Name)
+				continue
 			}
-			dependOnCount++
+			r.recorder.Eventf(base, corev1.EventTypeNormal, v1alpha1.PhaseChangeReason, "'%s' => '%s',dependOns can never be satisfied",
+				utils.FirstNotNull(currentStepPhase, v1alpha1.StepPending), v1alpha1.StepSkipped)
+		}
+	}
+}
+
+// hasFailedStep 有step 回滚或失败
+func hasFailedStep(steps []v1alpha1.Step) bool {
+	for _, step := range steps {
+		switch step.Status.Phase {
+		case v1alpha1.StepRollingBack, v1alpha1.StepRollBacked, v1alpha1.StepFailed:
+			return true
 		}
-		// 依赖的任务全部进入指定状态
-		if dependOnCount >= len(workflowStep.DependOns) {
-			ret = append(ret, stepMap[workflowStep.Name])
+	}
+	return false
+}
+
+// handlingFailure 有step 回滚或失败，并且处理失败的step 还在运行或者还会运行
+func handlingFailure(workflow *v1alpha1.Workflow, steps []v1alpha1.Step) bool {
+	if !hasFailedStep(steps) {
+		return false
+	}
+	phases := stepGroupPhases(workflow, steps)
+	unsatisfiable := map[string]bool{}
+	for _, u := range dag.FindUnsatisfiable(workflow.Spec.Steps, phases) {
+		unsatisfiable[u.Step] = true
+	}
+	for _, ws := range workflow.Spec.Steps {
+		if !dag.HandlesFailure(ws) || unsatisfiable[ws.Name] {
+			continue
+		}
+		switch phases[ws.Name] {
+		case "", v1alpha1.StepPending, v1alpha1.StepRunning:
+			return true
+		}
+	}
+	return false
+}
+
+// workflowVariables when、withParam 和parameters 模板中都可以引用的变量：workflow 的parameters.xx（也可以写作workflow.parameters.xx），
+// 以及step 汇总的attributes.xx
+func workflowVariables(workflow *v1alpha1.Workflow) map[string]string {
+	variables := map[string]string{}
+	for k, v := range workflow.Spec.Parameters {
+		variables["parameters."+k] = v
+		variables["workflow.parameters."+k] = v
+	}
+	for k, v := range workflow.Status.Attributes {
+		variables["attributes."+k] = v
+	}
+	return variables
+}
+
+// stepVariables when 和parameters 模板中还可以引用step 的steps.<step>.attributes.xx，展开的step 还可以引用item。
+// withItems/withParam 展开的step 有多个，不能按step 名称引用其attributes
+func stepVariables(workflow *v1alpha1.Workflow, step *v1alpha1.Step, groups map[string][]v1alpha1.Step) map[string]string {
+	variables := workflowVariables(workflow)
+	for stepName, group := range groups {
+		if len(group) != 1 || stepIndex(&group[0]) >= 0 {
+			continue
+		}
+		for k, v := range group[0].Status.Attributes {
+			variables["steps."+stepName+".attributes."+k] = v
+		}
+	}
+	if _, ok := step.Labels[stepIndexLabel]; ok {
+		variables[itemParameter] = step.Spec.Parameters[itemParameter]
+	}
+	return variables
+}
+
+// templateVariables step parameters 的模板中可以引用的变量，在stepVariables 的基础上还可以引用上游step 的steps.<step>.outputs.<path>
+func templateVariables(workflow *v1alpha1.Workflow, step *v1alpha1.Step, groups map[string][]v1alpha1.Step) map[string]string {
+	variables := stepVariables(workflow, step, groups)
+	// steps.<step>.outputs.<path> 按JSON path 读取step 的status.outputs，对象和数组为JSON
+	for _, v := range step.Spec.Parameters {
+		for _, variable := range expression.TemplateVariables(v) {
+			parts := strings.SplitN(variable, ".", 4)
+			if len(parts) != 4 || parts[0] != "steps" || parts[2] != "outputs" {
+				continue
+			}
+			group := groups[parts[1]]
+			if len(group) != 1 || stepIndex(&group[0]) >= 0 {
+				continue
+			}
+			if result := stepinterface.GetOutput(&group[0], parts[3]); result.Exists() {
+				variables[variable] = result.String()
+			}
+		}
+	}
+	return variables
+}
+
+// resolveStepParameters 解析step parameters 中的 {{...}} 模板，只返回带有模板的parameter
+func resolveStepParameters(workflow *v1alpha1.Workflow, step *v1alpha1.Step, groups map[string][]v1alpha1.Step) (map[string]string, error) {
+	var variables map[string]string
+	resolved := map[string]string{}
+	for k, v := range step.Spec.Parameters {
+		if !expression.IsTemplate(v) {
+			continue
+		}
+		if variables == nil {
+			variables = templateVariables(workflow, step, groups)
+		}
+		value, err := expression.Render(v, variables)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %v", k, err)
+		}
+		resolved[k] = value
+	}
+	if len(resolved) == 0 {
+		return nil, nil
+	}
+	return resolved, nil
+}
+
+// findCanRunningStep 返回dependOns 已满足的step，step.Status.DependOns 为已满足的依赖step
+func (r *workflowReconciler) findCanRunningStep(workflow *v1alpha1.Workflow, steps []v1alpha1.Step) []v1alpha1.Step {
+	groups := groupSteps(steps)
+	ret := make([]v1alpha1.Step, 0)
+	for _, workflowStep := range workflow.Spec.Steps {
+		// 判断 当前 workflowStep 是否可以running，没有依赖，可以执行
+		satisfied, dependOns := r.dependOnsSatisfied(workflow, workflowStep, groups)
+		if !satisfied {
+			continue
+		}
+		for _, step := range groups[workflowStep.Name] {
+			step.Status.DependOns = dependOns
+			ret = append(ret, step)
 		}
 	}
 	return ret
 }
+
+// dependOnsSatisfied 依赖的step 是否进入了指定状态，withItems/withParam 展开的step 要整组进入指定状态
+func (r *workflowReconciler) dependOnsSatisfied(workflow *v1alpha1.Workflow, ws v1alpha1.WorkflowStep, groups map[string][]v1alpha1.Step) (bool, []string) {
+	return dag.Satisfied(ws, func(dependOn v1alpha1.DependOn) bool {
+		group := groups[dependOn.Name]
+		return matchDependOn(dependOn, stepGroupPhase(workflow, dependOn.Name, group), group)
+	})
+}
+
+// matchDependOn 依赖step 是否进入了指定状态
+func matchDependOn(dependOn v1alpha1.DependOn, dependOnStepPhase v1alpha1.StepPhase, dependOnSteps []v1alpha1.Step) bool {
+	// 依赖step 被跳过时视为成功，下游step 继续运行
+	if dependOn.Phase == v1alpha1.StepSuccess && dependOnStepPhase == v1alpha1.StepSkipped {
+		return true
+	}
+	// 依赖step 的phase 不对
+	if dependOn.Phase != dependOnStepPhase {
+		return false
+	}
+	// 依赖step 的ResourceStatus 不对，如果有的话
+	if len(dependOn.ResourceStatus) > 0 {
+		for _, dependOnStep := range dependOnSteps {
+			if dependOn.ResourceStatus != dependOnStep.Status.Resource.Status {
+				return false
+			}
+		}
+	}
+	return true
+}
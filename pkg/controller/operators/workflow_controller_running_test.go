@@ -0,0 +1,271 @@
+package operators
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/expression"
+)
+
+func newRunningTestStep(name string, phase v1alpha1.StepPhase) v1alpha1.Step {
+	step := v1alpha1.Step{}
+	step.Name = "example-" + name
+	step.Labels = map[string]string{"step": name}
+	step.Status.Phase = phase
+	return step
+}
+
+func TestFindCanRunningStepSkipped(t *testing.T) {
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{Steps: []v1alpha1.WorkflowStep{
+		{Name: "a"},
+		{Name: "b", DependOns: []v1alpha1.DependOn{{Name: "a", Phase: v1alpha1.StepSuccess}}},
+		{Name: "c", DependOns: []v1alpha1.DependOn{{Name: "a", Phase: v1alpha1.StepSkipped}}},
+		{Name: "d", DependOns: []v1alpha1.DependOn{{Name: "a", Phase: v1alpha1.StepRunning}}},
+	}}}
+	steps := []v1alpha1.Step{
+		newRunningTestStep("a", v1alpha1.StepSkipped),
+		newRunningTestStep("b", v1alpha1.StepPending),
+		newRunningTestStep("c", v1alpha1.StepPending),
+		newRunningTestStep("d", v1alpha1.StepPending),
+	}
+	r := &workflowReconciler{}
+	got := map[string]bool{}
+	for _, step := range r.findCanRunningStep(workflow, steps) {
+		got[step.Labels["step"]] = true
+	}
+	// 被跳过的step 满足Success 依赖
+	if !got["b"] || !got["c"] || got["d"] {
+		t.Errorf("expect b and c can run, got %v", got)
+	}
+}
+
+func TestFindCanRunningStepAny(t *testing.T) {
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{Steps: []v1alpha1.WorkflowStep{
+		{Name: "a"},
+		{Name: "b"},
+		{Name: "c", DependMode: v1alpha1.DependAny, DependOns: []v1alpha1.DependOn{
+			{Name: "a", Phase: v1alpha1.StepSuccess}, {Name: "b", Phase: v1alpha1.StepSuccess},
+		}},
+	}}}
+	steps := []v1alpha1.Step{
+		newRunningTestStep("a", v1alpha1.StepRunning),
+		newRunningTestStep("b", v1alpha1.StepSuccess),
+		newRunningTestStep("c", v1alpha1.StepPending),
+	}
+	r := &workflowReconciler{}
+	for _, step := range r.findCanRunningStep(workflow, steps) {
+		if step.Labels["step"] != "c" {
+			continue
+		}
+		if len(step.Status.DependOns) != 1 || step.Status.DependOns[0] != "b" {
+			t.Errorf("expect c relies on b, got %v", step.Status.DependOns)
+		}
+		return
+	}
+	t.Errorf("expect c can run")
+}
+
+func TestWorkflowVariables(t *testing.T) {
+	workflow := &v1alpha1.Workflow{
+		Spec:   v1alpha1.WorkflowSpec{Parameters: map[string]string{"env": "prod"}},
+		Status: v1alpha1.WorkflowStatus{Attributes: map[string]string{"needsMigration": "false"}},
+	}
+	ok, err := expression.EvaluateBool("[parameters.env] == 'prod' && [attributes.needsMigration] == false", workflowVariables(workflow))
+	if err != nil || !ok {
+		t.Errorf("expect true, got %v %v", ok, err)
+	}
+	// when 中也可以使用parameters 模板的写法
+	step1 := newRunningTestStep("step1", v1alpha1.StepSuccess)
+	step1.Status.Attributes = map[string]string{"vpcId": "vpc-1"}
+	step := newRunningTestStep("step2", v1alpha1.StepPending)
+	variables := stepVariables(workflow, &step, groupSteps([]v1alpha1.Step{step1, step}))
+	ok, err = expression.EvaluateBool("[workflow.parameters.env] == 'prod' && [steps.step1.attributes.vpcId] == 'vpc-1'", variables)
+	if err != nil || !ok {
+		t.Errorf("expect true, got %v %v", ok, err)
+	}
+}
+
+func TestSeeAsRollBackedWorkflowSkipped(t *testing.T) {
+	workflow := &v1alpha1.Workflow{
+		Spec:   v1alpha1.WorkflowSpec{Steps: []v1alpha1.WorkflowStep{{Name: "a"}, {Name: "b"}}},
+		Status: v1alpha1.WorkflowStatus{StepPhases: map[v1alpha1.StepPhase]int{v1alpha1.StepSkipped: 2}},
+	}
+	if !seeAsRollBackedWorkflow(workflow) {
+		t.Errorf("expect workflow with all steps skipped see as rollbacked")
+	}
+	step := newRunningTestStep("a", v1alpha1.StepSkipped)
+	if !seeAsRollBackedStep(&step) {
+		t.Errorf("expect skipped step see as rollbacked")
+	}
+}
+
+func TestResolveStepParameters(t *testing.T) {
+	workflow := &v1alpha1.Workflow{
+		Spec:   v1alpha1.WorkflowSpec{Parameters: map[string]string{"region": "us-east"}},
+		Status: v1alpha1.WorkflowStatus{Attributes: map[string]string{"cluster": "c1"}},
+	}
+	step1 := newRunningTestStep("step1", v1alpha1.StepSuccess)
+	step1.Status.Attributes = map[string]string{"vpcId": "vpc-1"}
+	step1.Status.Outputs = &runtime.RawExtension{Raw: []byte(`{"subnets":[{"id":"subnet-1"},{"id":"subnet-2"}]}`)}
+	step := newRunningTestStep("step2", v1alpha1.StepPending)
+	step.Spec.Parameters = map[string]string{
+		"vpc":    "{{steps.step1.attributes.vpcId}}",
+		"region": "{{ workflow.parameters.region }}",
+		"size":   "10",
+		"subnet": "{{steps.step1.outputs.subnets.1.id}}",
+		"all":    "{{steps.step1.outputs.subnets.#.id}}",
+		"env":    "{{parameters.region}}-{{attributes.cluster}}",
+	}
+	groups := groupSteps([]v1alpha1.Step{step1, step})
+	resolved, err := resolveStepParameters(workflow, &step, groups)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	// 只记录带有模板的parameter
+	expect := map[string]string{"vpc": "vpc-1", "region": "us-east", "subnet": "subnet-2", "all": `["subnet-1","subnet-2"]`, "env": "us-east-c1"}
+	if !reflect.DeepEqual(resolved, expect) {
+		t.Errorf("expect %v, got %v", expect, resolved)
+	}
+	step.Status.ResolvedParameters = resolved
+	applyResolvedParameters(&step)
+	if step.Spec.Parameters["vpc"] != "vpc-1" || step.Spec.Parameters["size"] != "10" {
+		t.Errorf("expect resolved parameters applied, got %v", step.Spec.Parameters)
+	}
+
+	step.Spec.Parameters = map[string]string{"subnet": "{{steps.step1.attributes.subnetId}}"}
+	if _, err = resolveStepParameters(workflow, &step, groups); err == nil {
+		t.Errorf("expect error for missing attribute")
+	}
+	step.Spec.Parameters = map[string]string{"subnet": "{{steps.step1.outputs.subnets.5.id}}"}
+	if _, err = resolveStepParameters(workflow, &step, groups); err == nil {
+		t.Errorf("expect error for missing output")
+	}
+}
+
+func TestReconcileRunningResolveParameters(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{
+		Parameters: map[string]string{"region": "us-east"},
+		Steps:      []v1alpha1.WorkflowStep{{Name: "a"}},
+	}}
+	workflow.Name = "example"
+	step := newRunningTestStep("a", v1alpha1.StepPending)
+	step.Namespace = "default"
+	step.Spec.Parameters = map[string]string{"region": "{{workflow.parameters.region}}"}
+	r := &workflowReconciler{
+		client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(&step).Build(),
+		log:      logr.Discard(),
+		recorder: record.NewFakeRecorder(10),
+	}
+	ctx := context.Background()
+	r.reconcileRunning(ctx, workflow, []v1alpha1.Step{step})
+	actual := &v1alpha1.Step{}
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: "default", Name: step.Name}, actual); err != nil {
+		t.Fatalf("get step error: %v", err)
+	}
+	if actual.Status.Phase != v1alpha1.StepRunning || actual.Status.ResolvedParameters["region"] != "us-east" {
+		t.Errorf("expect step running with resolved parameters, got %+v", actual.Status)
+	}
+	// spec 中保留模板
+	if actual.Spec.Parameters["region"] != "{{workflow.parameters.region}}" {
+		t.Errorf("expect template kept in spec, got %v", actual.Spec.Parameters)
+	}
+}
+
+// newFailureHandlingTest Running 的workflow example，step a 或b 失败时运行c
+func newFailureHandlingTest(t *testing.T, callback string, a, b, c v1alpha1.Step) (*workflowReconciler, ctrl.Request) {
+	r, req := newRetryForwardTest(t, callback, a, b, c)
+	ctx := context.Background()
+	workflow := &v1alpha1.Workflow{}
+	if err := r.client.Get(ctx, req.NamespacedName, workflow); err != nil {
+		t.Fatalf("get workflow error: %v", err)
+	}
+	workflow.Spec.Steps[2].DependMode = v1alpha1.DependAny
+	workflow.Spec.Steps[2].DependOns = []v1alpha1.DependOn{{Name: "a", Phase: v1alpha1.StepFailed}, {Name: "b", Phase: v1alpha1.StepFailed}}
+	workflow.Status.Phase = v1alpha1.WorkflowRunning
+	if err := r.client.Update(ctx, workflow); err != nil {
+		t.Fatalf("update workflow error: %v", err)
+	}
+	return r, req
+}
+
+// reconcileTestWorkflow reconcile 后返回最新的workflow
+func reconcileTestWorkflow(t *testing.T, r *workflowReconciler, req ctrl.Request) *v1alpha1.Workflow {
+	ctx := context.Background()
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile error: %v", err)
+	}
+	workflow := &v1alpha1.Workflow{}
+	if err := r.client.Get(ctx, req.NamespacedName, workflow); err != nil {
+		t.Fatalf("get workflow error: %v", err)
+	}
+	return workflow
+}
+
+func TestReconcileRunningHandlesFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	defer server.Close()
+	a := newRunningTestStep("a", v1alpha1.StepFailed)
+	b := newRunningTestStep("b", v1alpha1.StepRunning)
+	c := newRunningTestStep("c", v1alpha1.StepPending)
+	r, req := newFailureHandlingTest(t, server.URL, a, b, c)
+	// a 失败后先运行c，workflow 不会失败，b 也不会回滚
+	workflow := reconcileTestWorkflow(t, r, req)
+	if workflow.Status.Phase != v1alpha1.WorkflowRunning {
+		t.Fatalf("expect workflow running, got %s", workflow.Status.Phase)
+	}
+	if phase := getRetryTestStep(t, r, &c).Status.Phase; phase != v1alpha1.StepRunning {
+		t.Fatalf("expect step c running, got %s", phase)
+	}
+	if phase := getRetryTestStep(t, r, &b).Status.Phase; phase != v1alpha1.StepRunning {
+		t.Fatalf("expect step b running, got %s", phase)
+	}
+	// c 运行结束后workflow 失败，回滚成功的step
+	for _, step := range []*v1alpha1.Step{&b, &c} {
+		actual := getRetryTestStep(t, r, step)
+		actual.Status.Phase = v1alpha1.StepSuccess
+		if err := r.client.Update(context.Background(), actual); err != nil {
+			t.Fatalf("update step error: %v", err)
+		}
+	}
+	workflow = reconcileTestWorkflow(t, r, req)
+	if workflow.Status.Phase != v1alpha1.WorkflowFailed {
+		t.Errorf("expect workflow failed, got %s", workflow.Status.Phase)
+	}
+	for _, step := range []*v1alpha1.Step{&b, &c} {
+		if phase := getRetryTestStep(t, r, step).Status.Phase; phase != v1alpha1.StepRollingBack {
+			t.Errorf("expect step %s rolling back, got %s", step.Name, phase)
+		}
+	}
+}
+
+func TestReconcileRunningSkipFailureHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	defer server.Close()
+	a := newRunningTestStep("a", v1alpha1.StepSuccess)
+	b := newRunningTestStep("b", v1alpha1.StepSuccess)
+	c := newRunningTestStep("c", v1alpha1.StepPending)
+	r, req := newFailureHandlingTest(t, server.URL, a, b, c)
+	// a、b 都成功了，c 被跳过，workflow 成功
+	if workflow := reconcileTestWorkflow(t, r, req); workflow.Status.Phase != v1alpha1.WorkflowRunning {
+		t.Fatalf("expect workflow running, got %s", workflow.Status.Phase)
+	}
+	if phase := getRetryTestStep(t, r, &c).Status.Phase; phase != v1alpha1.StepSkipped {
+		t.Fatalf("expect step c skipped, got %s", phase)
+	}
+	if workflow := reconcileTestWorkflow(t, r, req); workflow.Status.Phase != v1alpha1.WorkflowSuccess {
+		t.Errorf("expect workflow success, got %s", workflow.Status.Phase)
+	}
+}
@@ -0,0 +1,49 @@
+package operators
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/dag"
+)
+
+// reconcileSpecWrong dependOns 有环、依赖永远无法满足或多个step 通过exportAttributes 写入同一个attribute 时回滚workflow，spec 有问题时返回true
+func (r *workflowReconciler) reconcileSpecWrong(ctx context.Context, workflow *v1alpha1.Workflow, steps []v1alpha1.Step) bool {
+	var err error
+	// 有step 失败后依赖无法满足是正常的，workflow 只是在等处理失败的step
+	if !hasFailedStep(steps) {
+		err = dag.Analyze(workflow.Spec.Steps, stepGroupPhases(workflow, steps))
+	}
+	if err == nil {
+		_, _, err = collectOutputs(workflow, steps)
+	}
+	if err == nil {
+		return false
+	}
+	r.rollbackSpecWrong(ctx, workflow, steps, err)
+	return true
+}
+
+// rollbackSpecWrong 因为spec 错误回滚workflow
+func (r *workflowReconciler) rollbackSpecWrong(ctx context.Context, workflow *v1alpha1.Workflow, steps []v1alpha1.Step, err error) {
+	log := r.log.WithValues("name", workflow.Name)
+	currentPhase := workflow.Status.Phase
+	// 还没有创建step 则不需要回滚
+	if len(steps) == 0 {
+		workflow.Status.Phase = v1alpha1.WorkflowRollBacked
+	} else {
+		workflow.Status.Phase = v1alpha1.WorkflowRollingBack
+	}
+	workflow.Status.Reason = v1alpha1.SpecWrongReason
+	workflow.Status.RunError = err.Error()
+	log.Info("workflow spec is wrong, start rollback", "error", err.Error())
+	r.recorder.Eventf(workflow, corev1.EventTypeWarning, v1alpha1.SpecWrongReason, "'%s' => '%s',%v",
+		currentPhase, workflow.Status.Phase, err)
+	// cancel 正在执行的step Run/Sync
+	r.controllerCtx.StepCanceler.Cancel(workflow.Namespace + workflow.Name)
+	if len(steps) > 0 {
+		r.reconcileRollingBack(ctx, workflow, steps)
+	}
+}
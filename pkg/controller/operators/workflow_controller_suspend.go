@@ -0,0 +1,35 @@
+package operators
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+// reconcileSuspend 按spec.suspend 更新Suspended condition，没有暂停过的workflow 不添加
+func (r *workflowReconciler) reconcileSuspend(workflow *v1alpha1.Workflow) {
+	suspended := meta.IsStatusConditionTrue(workflow.Status.Conditions, v1alpha1.WorkflowSuspended)
+	if workflow.Spec.Suspend == suspended {
+		return
+	}
+	if !workflow.Spec.Suspend && meta.FindStatusCondition(workflow.Status.Conditions, v1alpha1.WorkflowSuspended) == nil {
+		return
+	}
+	condition := metav1.Condition{
+		Type:               v1alpha1.WorkflowSuspended,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: workflow.Generation,
+		Reason:             v1alpha1.SuspendedReason,
+		Message:            "workflow is suspended by spec.suspend",
+	}
+	if !workflow.Spec.Suspend {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = v1alpha1.ResumedReason
+		condition.Message = "workflow is resumed"
+	}
+	meta.SetStatusCondition(&workflow.Status.Conditions, condition)
+	r.log.WithValues("name", workflow.Name).Info(condition.Message, "phase", workflow.Status.Phase)
+	r.recorder.Eventf(workflow, corev1.EventTypeNormal, condition.Reason, condition.Message)
+}
@@ -0,0 +1,73 @@
+package operators
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+func TestReconcileSuspend(t *testing.T) {
+	r := &workflowReconciler{log: logr.Discard(), recorder: record.NewFakeRecorder(10)}
+	workflow := &v1alpha1.Workflow{}
+	// 没有暂停过的workflow 不添加condition
+	r.reconcileSuspend(workflow)
+	if len(workflow.Status.Conditions) != 0 {
+		t.Errorf("expect no condition, got %v", workflow.Status.Conditions)
+	}
+	workflow.Spec.Suspend = true
+	r.reconcileSuspend(workflow)
+	condition := meta.FindStatusCondition(workflow.Status.Conditions, v1alpha1.WorkflowSuspended)
+	if condition == nil || condition.Status != metav1.ConditionTrue || condition.Reason != v1alpha1.SuspendedReason {
+		t.Errorf("expect suspended condition, got %v", workflow.Status.Conditions)
+	}
+	workflow.Spec.Suspend = false
+	r.reconcileSuspend(workflow)
+	condition = meta.FindStatusCondition(workflow.Status.Conditions, v1alpha1.WorkflowSuspended)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != v1alpha1.ResumedReason {
+		t.Errorf("expect resumed condition, got %v", workflow.Status.Conditions)
+	}
+}
+
+func TestReconcileRunningSuspended(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{
+		Suspend: true,
+		Steps:   []v1alpha1.WorkflowStep{{Name: "a"}},
+	}}
+	workflow.Name = "example"
+	step := newRunningTestStep("a", v1alpha1.StepPending)
+	step.Namespace = "default"
+	r := &workflowReconciler{
+		client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(&step).Build(),
+		log:      logr.Discard(),
+		recorder: record.NewFakeRecorder(10),
+	}
+	ctx := context.Background()
+	r.reconcileRunning(ctx, workflow, []v1alpha1.Step{step})
+	actual := &v1alpha1.Step{}
+	if err := r.client.Get(ctx, client.ObjectKeyFromObject(&step), actual); err != nil {
+		t.Fatalf("get step error: %v", err)
+	}
+	if actual.Status.Phase != v1alpha1.StepPending {
+		t.Fatalf("expect step pending while suspended, got %s", actual.Status.Phase)
+	}
+	// 恢复后继续运行
+	workflow.Spec.Suspend = false
+	r.reconcileRunning(ctx, workflow, []v1alpha1.Step{*actual})
+	if err := r.client.Get(ctx, client.ObjectKeyFromObject(&step), actual); err != nil {
+		t.Fatalf("get step error: %v", err)
+	}
+	if actual.Status.Phase != v1alpha1.StepRunning {
+		t.Errorf("expect step running after resumed, got %s", actual.Status.Phase)
+	}
+}
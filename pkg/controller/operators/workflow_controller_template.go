@@ -0,0 +1,116 @@
+package operators
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+// reconcileTemplate workflow 开始时解析workflowTemplateRef 并把结果快照到status，之后按快照运行，模板的修改不影响已经开始的workflow。
+// 只在内存中替换spec，调用方负责在patch 之前恢复spec
+func (r *workflowReconciler) reconcileTemplate(ctx context.Context, workflow *v1alpha1.Workflow) error {
+	if workflow.Status.StoredSpec == nil && workflow.DeletionTimestamp.IsZero() && notStarted(workflow) {
+		ref := workflow.Spec.WorkflowTemplateRef
+		templateSpec, err := r.getWorkflowTemplate(ctx, workflow.Namespace, ref)
+		if err != nil && !k8sapierrors.IsNotFound(err) {
+			return err
+		}
+		var stored *v1alpha1.WorkflowSpec
+		if err == nil {
+			stored, err = mergeWorkflowTemplate(workflow, templateSpec)
+		}
+		if err != nil {
+			// 模板不存在或参数不对，workflow 无法运行
+			r.rollbackSpecWrong(ctx, workflow, nil, fmt.Errorf("resolve workflow template %s error: %v", ref.Name, err))
+			return nil
+		}
+		workflow.Status.StoredSpec = stored
+		r.recorder.Eventf(workflow, corev1.EventTypeNormal, v1alpha1.TemplateResolvedReason, "resolved workflow template '%s'", ref.Name)
+	}
+	applyStoredSpec(workflow)
+	return nil
+}
+
+// notStarted workflow 还没有创建step，Running 表示刚被queue 调度
+func notStarted(workflow *v1alpha1.Workflow) bool {
+	switch workflow.Status.Phase {
+	case "", v1alpha1.WorkflowPending, v1alpha1.WorkflowRunning:
+		return len(workflow.Status.StepPhases) == 0
+	}
+	return false
+}
+
+func (r *workflowReconciler) getWorkflowTemplate(ctx context.Context, namespace string, ref *v1alpha1.WorkflowTemplateRef) (*v1alpha1.WorkflowTemplateSpec, error) {
+	if ref.ClusterScope {
+		template := &v1alpha1.ClusterWorkflowTemplate{}
+		if err := r.client.Get(ctx, types.NamespacedName{Name: ref.Name}, template); err != nil {
+			return nil, err
+		}
+		return &template.Spec, nil
+	}
+	template := &v1alpha1.WorkflowTemplate{}
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, template); err != nil {
+		return nil, err
+	}
+	return &template.Spec, nil
+}
+
+// mergeWorkflowTemplate 按模板生成workflow 运行使用的spec，parameters 依次来自模板、输入参数的默认值和workflow。
+// workflow 只能设置模板声明的参数，没有默认值的参数必须设置；callback、activeDeadlineSeconds 没有设置时使用模板的
+func mergeWorkflowTemplate(workflow *v1alpha1.Workflow, templateSpec *v1alpha1.WorkflowTemplateSpec) (*v1alpha1.WorkflowSpec, error) {
+	if templateSpec.Template.WorkflowTemplateRef != nil {
+		return nil, fmt.Errorf("template can not reference another template")
+	}
+	spec := &v1alpha1.WorkflowSpec{
+		Parameters:            map[string]string{},
+		Steps:                 templateSpec.Template.DeepCopy().Steps,
+		Callback:              templateSpec.Template.Callback,
+		ActiveDeadlineSeconds: templateSpec.Template.ActiveDeadlineSeconds,
+	}
+	for k, v := range templateSpec.Template.Parameters {
+		spec.Parameters[k] = v
+	}
+	declared := map[string]bool{}
+	for _, input := range templateSpec.Inputs {
+		declared[input.Name] = true
+		if input.Default != nil {
+			spec.Parameters[input.Name] = *input.Default
+		}
+	}
+	for k, v := range workflow.Spec.Parameters {
+		if !declared[k] {
+			return nil, fmt.Errorf("parameter %s is not declared by template", k)
+		}
+		spec.Parameters[k] = v
+	}
+	for _, input := range templateSpec.Inputs {
+		if _, ok := spec.Parameters[input.Name]; !ok {
+			return nil, fmt.Errorf("parameter %s is required by template", input.Name)
+		}
+	}
+	if len(workflow.Spec.Callback.Url) > 0 {
+		spec.Callback = workflow.Spec.Callback
+	}
+	if workflow.Spec.ActiveDeadlineSeconds > 0 {
+		spec.ActiveDeadlineSeconds = workflow.Spec.ActiveDeadlineSeconds
+	}
+	return spec, nil
+}
+
+// applyStoredSpec 用status 中的快照替换workflow 的parameters、steps、callback、activeDeadlineSeconds
+func applyStoredSpec(workflow *v1alpha1.Workflow) {
+	stored := workflow.Status.StoredSpec
+	if stored == nil {
+		return
+	}
+	stored = stored.DeepCopy()
+	workflow.Spec.Parameters = stored.Parameters
+	workflow.Spec.Steps = stored.Steps
+	workflow.Spec.Callback = stored.Callback
+	workflow.Spec.ActiveDeadlineSeconds = stored.ActiveDeadlineSeconds
+}
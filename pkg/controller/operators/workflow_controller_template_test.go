@@ -0,0 +1,128 @@
+package operators
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/controller/manager"
+	"github.com/qiankunli/workflow/pkg/utils/cancel"
+)
+
+func newTestWorkflowTemplateSpec() *v1alpha1.WorkflowTemplateSpec {
+	region := "us"
+	return &v1alpha1.WorkflowTemplateSpec{
+		Inputs: []v1alpha1.TemplateParameter{{Name: "env"}, {Name: "region", Default: &region}},
+		Template: v1alpha1.WorkflowSpec{
+			Parameters:            map[string]string{"owner": "ops"},
+			Steps:                 []v1alpha1.WorkflowStep{{Name: "a", StepTemplate: v1alpha1.StepSpec{Type: "empty"}}},
+			ActiveDeadlineSeconds: 60,
+		},
+	}
+}
+
+func TestMergeWorkflowTemplate(t *testing.T) {
+	cases := []struct {
+		name       string
+		parameters map[string]string
+		expect     map[string]string
+		expectErr  string
+	}{
+		{
+			name:       "default",
+			parameters: map[string]string{"env": "prod"},
+			expect:     map[string]string{"owner": "ops", "env": "prod", "region": "us"},
+		},
+		{
+			name:       "override default",
+			parameters: map[string]string{"env": "prod", "region": "eu"},
+			expect:     map[string]string{"owner": "ops", "env": "prod", "region": "eu"},
+		},
+		{
+			name:      "missing required",
+			expectErr: "parameter env is required",
+		},
+		{
+			name:       "not declared",
+			parameters: map[string]string{"env": "prod", "owner": "dev"},
+			expectErr:  "parameter owner is not declared",
+		},
+	}
+	for _, c := range cases {
+		workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{Parameters: c.parameters}}
+		spec, err := mergeWorkflowTemplate(workflow, newTestWorkflowTemplateSpec())
+		if len(c.expectErr) > 0 {
+			if err == nil || !strings.Contains(err.Error(), c.expectErr) {
+				t.Errorf("%s: expect error %q, got %v", c.name, c.expectErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: expect no error, got %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(spec.Parameters, c.expect) {
+			t.Errorf("%s: expect %v, got %v", c.name, c.expect, spec.Parameters)
+		}
+		if len(spec.Steps) != 1 || spec.ActiveDeadlineSeconds != 60 {
+			t.Errorf("%s: expect steps and activeDeadlineSeconds from template, got %+v", c.name, spec)
+		}
+	}
+}
+
+func TestReconcileTemplate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	template := &v1alpha1.ClusterWorkflowTemplate{Spec: *newTestWorkflowTemplateSpec()}
+	template.Name = "deploy"
+	r := &workflowReconciler{
+		client:        fake.NewClientBuilder().WithScheme(scheme).WithObjects(template).Build(),
+		controllerCtx: &manager.ControllerContext{StepCanceler: cancel.NewGroupCanceler()},
+		log:           logr.Discard(),
+		recorder:      record.NewFakeRecorder(10),
+	}
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{
+		Parameters:          map[string]string{"env": "prod"},
+		WorkflowTemplateRef: &v1alpha1.WorkflowTemplateRef{Name: "deploy", ClusterScope: true},
+	}}
+	workflow.Name = "example"
+	workflow.Namespace = "default"
+	ctx := context.Background()
+	if err := r.reconcileTemplate(ctx, workflow); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if workflow.Status.StoredSpec == nil || len(workflow.Spec.Steps) != 1 || workflow.Spec.Parameters["region"] != "us" {
+		t.Fatalf("expect template stored and applied, got %+v", workflow)
+	}
+
+	// 模板的修改不影响已经开始的workflow
+	template.Spec.Template.Steps = append(template.Spec.Template.Steps, v1alpha1.WorkflowStep{Name: "b"})
+	if err := r.client.Update(ctx, template); err != nil {
+		t.Fatalf("update template error: %v", err)
+	}
+	workflow.Spec.Steps = nil
+	if err := r.reconcileTemplate(ctx, workflow); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if len(workflow.Spec.Steps) != 1 {
+		t.Errorf("expect steps from snapshot, got %v", workflow.Spec.Steps)
+	}
+
+	// 模板不存在时workflow 无法运行
+	missing := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{WorkflowTemplateRef: &v1alpha1.WorkflowTemplateRef{Name: "missing"}}}
+	missing.Name = "missing"
+	missing.Namespace = "default"
+	if err := r.reconcileTemplate(ctx, missing); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if missing.Status.Phase != v1alpha1.WorkflowRollBacked || missing.Status.Reason != v1alpha1.SpecWrongReason {
+		t.Errorf("expect workflow rollbacked for spec wrong, got %+v", missing.Status)
+	}
+}
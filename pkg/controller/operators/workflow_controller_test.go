@@ -0,0 +1,51 @@
+package operators
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/constants"
+)
+
+func TestSkipRollback(t *testing.T) {
+	workflow := &v1alpha1.Workflow{}
+	workflow.Annotations = map[string]string{constants.AnnotationSkipRollback: "true"}
+	workflow.Status.Phase = v1alpha1.WorkflowRunning
+	if skipRollback(workflow) {
+		t.Errorf("expect running workflow rolls back")
+	}
+	workflow.Status.Phase = v1alpha1.WorkflowSuccess
+	if !skipRollback(workflow) {
+		t.Errorf("expect successful workflow with annotation skips rollback")
+	}
+}
+
+func TestReleaseSteps(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	step := newRunningTestStep("a", v1alpha1.StepSuccess)
+	step.Namespace = "default"
+	controllerutil.AddFinalizer(&step, constants.FinalizersWorkflow)
+	r := &workflowReconciler{
+		client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&step).Build(),
+		log:    logr.Discard(),
+	}
+	ctx := context.Background()
+	if err := r.releaseSteps(ctx, []v1alpha1.Step{step}); err != nil {
+		t.Fatalf("release steps error: %v", err)
+	}
+	actual := &v1alpha1.Step{}
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: "default", Name: step.Name}, actual); err != nil {
+		t.Fatalf("get step error: %v", err)
+	}
+	if controllerutil.ContainsFinalizer(actual, constants.FinalizersWorkflow) {
+		t.Errorf("expect finalizer removed, got %v", actual.Finalizers)
+	}
+}
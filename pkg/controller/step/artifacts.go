@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/artifact"
+)
+
+var artifactStorage artifact.Storage
+
+// SetArtifactStorage sets the storage used by PutArtifact and GetArtifact
+func SetArtifactStorage(s artifact.Storage) {
+	artifactStorage = s
+}
+
+// PutArtifact stores data as the artifact name of the step, the object is owned by the workflow so it is garbage
+// collected with the workflow. Only the reference is recorded in step.Status.Artifacts
+func PutArtifact(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step, name string, data []byte) error {
+	if artifactStorage == nil {
+		return fmt.Errorf("artifact storage is not set")
+	}
+	if msgs := validation.IsDNS1123Label(name); len(msgs) > 0 {
+		return fmt.Errorf("artifact name %s is invalid: %v", name, msgs)
+	}
+	if len(data) > artifact.MaxSize {
+		return fmt.Errorf("artifact %s is too large: %d > %d bytes", name, len(data), artifact.MaxSize)
+	}
+	// typed 对象的TypeMeta 为空，owner reference 需要GVK
+	owner := workflow.DeepCopy()
+	owner.SetGroupVersionKind(v1alpha1.GroupVersion.WithKind("Workflow"))
+	objectName := artifact.ObjectName(step.Name, name)
+	if err := artifactStorage.Put(ctx, step.Namespace, objectName, data, owner); err != nil {
+		return err
+	}
+	ref := v1alpha1.ArtifactRef{
+		Name:       name,
+		Storage:    artifactStorage.Kind(),
+		ObjectName: objectName,
+		Key:        artifact.DataKey,
+		Size:       int64(len(data)),
+	}
+	for i := range step.Status.Artifacts {
+		if step.Status.Artifacts[i].Name == name {
+			step.Status.Artifacts[i] = ref
+			return nil
+		}
+	}
+	step.Status.Artifacts = append(step.Status.Artifacts, ref)
+	return nil
+}
+
+// GetArtifact reads the artifact name written by the step stepName of the workflow, stepName is <step>-<index> for
+// steps expanded by withItems/withParam
+func GetArtifact(ctx context.Context, workflow *v1alpha1.Workflow, stepName, name string) ([]byte, error) {
+	if artifactStorage == nil {
+		return nil, fmt.Errorf("artifact storage is not set")
+	}
+	objectName := artifact.ObjectName(fmt.Sprintf("%s-%s", workflow.Name, stepName), name)
+	return artifactStorage.Get(ctx, workflow.Namespace, objectName)
+}
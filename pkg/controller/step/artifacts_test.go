@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/artifact"
+)
+
+func TestArtifacts(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	storage, _ := artifact.NewStorage(v1alpha1.ConfigMapArtifactStorage, c)
+	SetArtifactStorage(storage)
+	defer SetArtifactStorage(nil)
+
+	workflow := &v1alpha1.Workflow{}
+	workflow.Name = "example"
+	workflow.Namespace = "default"
+	workflow.UID = "uid"
+	step := &v1alpha1.Step{}
+	step.Name = "example-a"
+	step.Namespace = "default"
+	ctx := context.Background()
+
+	if err := PutArtifact(ctx, workflow, step, "bad.name", nil); err == nil {
+		t.Errorf("expect error for invalid artifact name")
+	}
+	if err := PutArtifact(ctx, workflow, step, "manifest", make([]byte, artifact.MaxSize+1)); err == nil {
+		t.Errorf("expect error for large artifact")
+	}
+	if err := PutArtifact(ctx, workflow, step, "manifest", []byte("v1")); err != nil {
+		t.Fatalf("put artifact error: %v", err)
+	}
+	if err := PutArtifact(ctx, workflow, step, "manifest", []byte("v22")); err != nil {
+		t.Fatalf("put artifact error: %v", err)
+	}
+	expect := v1alpha1.ArtifactRef{Name: "manifest", Storage: v1alpha1.ConfigMapArtifactStorage, ObjectName: "example-a.manifest", Key: artifact.DataKey, Size: 3}
+	if len(step.Status.Artifacts) != 1 || step.Status.Artifacts[0] != expect {
+		t.Errorf("expect %+v, got %+v", expect, step.Status.Artifacts)
+	}
+	data, err := GetArtifact(ctx, workflow, "a", "manifest")
+	if err != nil || string(data) != "v22" {
+		t.Errorf("expect v22, got %s %v", data, err)
+	}
+	// artifact 随workflow 一起被回收
+	configmap := &corev1.ConfigMap{}
+	if err = c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "example-a.manifest"}, configmap); err != nil {
+		t.Fatalf("get configmap error: %v", err)
+	}
+	if refs := configmap.OwnerReferences; len(refs) != 1 || refs[0].Kind != "Workflow" || refs[0].Name != "example" {
+		t.Errorf("expect owned by workflow, got %+v", refs)
+	}
+}
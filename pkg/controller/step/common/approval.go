@@ -0,0 +1,116 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/constants"
+	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
+	"github.com/qiankunli/workflow/pkg/options"
+)
+
+func init() {
+	stepinterface.FactoryV2["approval"] = NewApproval
+}
+
+const (
+	// ApprovalTimeoutParameter 等待审批的时间，比如24h，为空表示一直等待
+	ApprovalTimeoutParameter = "timeout"
+	// ApprovalDefaultOutcomeParameter 超时后的结果，默认为rejected
+	ApprovalDefaultOutcomeParameter = "defaultOutcome"
+	// ApprovalApproved 通过审批
+	ApprovalApproved = "approved"
+	// ApprovalRejected 拒绝审批
+	ApprovalRejected = "rejected"
+	// ApproverAttribute status.resource.attributes 中记录审批人的key
+	ApproverAttribute = "approver"
+	// approverTimeout 超时后按默认结果审批时记录的审批人
+	approverTimeout = "timeout"
+)
+
+// Approval 一直处于Running，直到step 或workflow 上的annotation 通过或拒绝，拒绝时回滚workflow
+type Approval struct {
+	timeout        time.Duration
+	defaultOutcome string
+	// 只有开启webhook 时approver annotation 才由webhook 按请求的用户设置，否则可以被任意伪造，不记录审批人
+	recordApprover bool
+}
+
+func NewApproval(cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (stepinterface.StepV2, error) {
+	a := &Approval{
+		defaultOutcome: ApprovalRejected,
+		recordApprover: cfg != nil && cfg.ControllerConfig != nil && cfg.ControllerConfig.Webhook.Enabled,
+	}
+	if v := step.Spec.Parameters[ApprovalTimeoutParameter]; len(v) > 0 {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s is invalid: %v", ApprovalTimeoutParameter, err)
+		}
+		a.timeout = timeout
+	}
+	if v := step.Spec.Parameters[ApprovalDefaultOutcomeParameter]; len(v) > 0 {
+		if !IsApprovalOutcome(v) {
+			return nil, fmt.Errorf("parameter %s must be %s or %s", ApprovalDefaultOutcomeParameter, ApprovalApproved, ApprovalRejected)
+		}
+		a.defaultOutcome = v
+	}
+	return a, nil
+}
+
+// IsApprovalOutcome 是否为approved 或rejected
+func IsApprovalOutcome(outcome string) bool {
+	return outcome == ApprovalApproved || outcome == ApprovalRejected
+}
+
+func (a *Approval) Run(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	step.Status.Resource.Status = "Waiting"
+	return nil
+}
+
+func (a *Approval) Poll(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (bool, stepinterface.StepError) {
+	outcome, approver := approvalOf(workflow, step)
+	if len(outcome) == 0 {
+		if a.timeout <= 0 || time.Since(step.Status.SubmittedAt.Time) < a.timeout {
+			return false, nil
+		}
+		outcome, approver = a.defaultOutcome, approverTimeout
+	} else if !a.recordApprover {
+		approver = ""
+	}
+	step.Status.Resource.Status = outcome
+	if len(approver) > 0 {
+		if step.Status.Resource.Attributes == nil {
+			step.Status.Resource.Attributes = map[string]string{}
+		}
+		step.Status.Resource.Attributes[ApproverAttribute] = approver
+	}
+	if outcome == ApprovalRejected && len(approver) > 0 {
+		return false, stepinterface.NewStepError(fmt.Errorf("rejected by %s", approver), false, false)
+	}
+	if outcome == ApprovalRejected {
+		return false, stepinterface.NewStepError(fmt.Errorf("rejected"), false, false)
+	}
+	return true, nil
+}
+
+func (a *Approval) Rollback(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	return nil
+}
+
+func (a *Approval) Sync(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	return nil
+}
+
+// approvalOf 先看step 上的annotation，再看workflow 上 approval.workflow.example.com/<step> 的annotation
+func approvalOf(workflow *v1alpha1.Workflow, step *v1alpha1.Step) (string, string) {
+	if outcome := step.Annotations[constants.AnnotationApproval]; IsApprovalOutcome(outcome) {
+		return outcome, step.Annotations[constants.AnnotationApprover]
+	}
+	stepName := step.Labels["step"]
+	if outcome := workflow.Annotations[constants.ApprovalAnnotationPrefix+stepName]; IsApprovalOutcome(outcome) {
+		return outcome, workflow.Annotations[constants.ApproverAnnotationPrefix+stepName]
+	}
+	return "", ""
+}
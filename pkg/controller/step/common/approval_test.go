@@ -0,0 +1,112 @@
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/constants"
+	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
+	"github.com/qiankunli/workflow/pkg/options"
+)
+
+func newApprovalStep(parameters map[string]string) *v1alpha1.Step {
+	step := &v1alpha1.Step{Spec: v1alpha1.StepSpec{Type: "approval", Parameters: parameters}}
+	step.Name = "example-gate"
+	step.Labels = map[string]string{"step": "gate"}
+	step.Status.SubmittedAt = metav1.Now()
+	return step
+}
+
+// newApprovalConfig webhookEnabled 为true 时记录审批人
+func newApprovalConfig(webhookEnabled bool) *options.Config {
+	cfg := options.NewDefaultConfig()
+	cfg.ControllerConfig.Webhook.Enabled = webhookEnabled
+	return cfg
+}
+
+func pollApproval(t *testing.T, cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (bool, stepinterface.StepError) {
+	s, err := stepinterface.NewStepV2(cfg, workflow, step)
+	if err != nil {
+		t.Fatalf("new approval step error: %v", err)
+	}
+	return s.(stepinterface.AsyncStep).Poll(context.Background(), workflow, step)
+}
+
+func TestApproval(t *testing.T) {
+	cfg := newApprovalConfig(true)
+	workflow := &v1alpha1.Workflow{}
+	step := newApprovalStep(nil)
+	if done, stepErr := pollApproval(t, cfg, workflow, step); done || stepErr != nil {
+		t.Fatalf("expect waiting, got %v %v", done, stepErr)
+	}
+
+	// workflow 上按step 名称审批
+	workflow.Annotations = map[string]string{
+		constants.ApprovalAnnotationPrefix + "gate": ApprovalApproved,
+		constants.ApproverAnnotationPrefix + "gate": "alice",
+	}
+	if done, stepErr := pollApproval(t, cfg, workflow, step); !done || stepErr != nil {
+		t.Fatalf("expect approved, got %v %v", done, stepErr)
+	}
+	if step.Status.Resource.Status != ApprovalApproved || step.Status.Resource.Attributes[ApproverAttribute] != "alice" {
+		t.Errorf("expect approved by alice, got %+v", step.Status.Resource)
+	}
+
+	// step 上的annotation 优先
+	step.Annotations = map[string]string{constants.AnnotationApproval: ApprovalRejected, constants.AnnotationApprover: "bob"}
+	done, stepErr := pollApproval(t, cfg, workflow, step)
+	if done || stepErr == nil || stepErr.Retryable() || stepErr.Error() != "rejected by bob" {
+		t.Fatalf("expect rejected by bob, got %v %v", done, stepErr)
+	}
+}
+
+func TestApprovalWebhookDisabled(t *testing.T) {
+	// 没有开启webhook 时approver annotation 可以被伪造，只按annotation 审批，不记录审批人
+	cfg := newApprovalConfig(false)
+	workflow := &v1alpha1.Workflow{}
+	step := newApprovalStep(nil)
+	step.Annotations = map[string]string{constants.AnnotationApproval: ApprovalApproved, constants.AnnotationApprover: "alice"}
+	if done, stepErr := pollApproval(t, cfg, workflow, step); !done || stepErr != nil {
+		t.Fatalf("expect approved, got %v %v", done, stepErr)
+	}
+	if _, ok := step.Status.Resource.Attributes[ApproverAttribute]; ok || step.Status.Resource.Status != ApprovalApproved {
+		t.Errorf("expect approved without approver, got %+v", step.Status.Resource)
+	}
+
+	step = newApprovalStep(nil)
+	step.Annotations = map[string]string{constants.AnnotationApproval: ApprovalRejected, constants.AnnotationApprover: "bob"}
+	done, stepErr := pollApproval(t, cfg, workflow, step)
+	if done || stepErr == nil || stepErr.Error() != "rejected" {
+		t.Fatalf("expect rejected without approver, got %v %v", done, stepErr)
+	}
+}
+
+func TestApprovalTimeout(t *testing.T) {
+	step := newApprovalStep(map[string]string{ApprovalTimeoutParameter: "1h", ApprovalDefaultOutcomeParameter: ApprovalApproved})
+	if done, stepErr := pollApproval(t, nil, &v1alpha1.Workflow{}, step); done || stepErr != nil {
+		t.Fatalf("expect waiting, got %v %v", done, stepErr)
+	}
+	step.Status.SubmittedAt = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	if done, stepErr := pollApproval(t, nil, &v1alpha1.Workflow{}, step); !done || stepErr != nil {
+		t.Fatalf("expect approved by default, got %v %v", done, stepErr)
+	}
+	if step.Status.Resource.Attributes[ApproverAttribute] != approverTimeout {
+		t.Errorf("expect approved by timeout, got %v", step.Status.Resource.Attributes)
+	}
+
+	// 默认超时后拒绝
+	step = newApprovalStep(map[string]string{ApprovalTimeoutParameter: "1h"})
+	step.Status.SubmittedAt = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	if done, stepErr := pollApproval(t, nil, &v1alpha1.Workflow{}, step); done || stepErr == nil {
+		t.Fatalf("expect rejected by default, got %v %v", done, stepErr)
+	}
+
+	step = newApprovalStep(map[string]string{ApprovalDefaultOutcomeParameter: "maybe"})
+	if _, err := stepinterface.NewStepV2(nil, &v1alpha1.Workflow{}, step); err == nil {
+		t.Errorf("expect error for invalid default outcome")
+	}
+}
@@ -0,0 +1,213 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
+	"github.com/qiankunli/workflow/pkg/options"
+)
+
+func init() {
+	stepinterface.FactoryV2["workflow"] = NewWorkflow
+}
+
+const (
+	// WorkflowSpecParameter 子workflow 的spec，yaml 或json 格式
+	WorkflowSpecParameter = "spec"
+	// WorkflowTemplateParameter 子workflow 引用的WorkflowTemplate，与spec 互斥
+	WorkflowTemplateParameter = "workflowTemplate"
+	// ClusterWorkflowTemplateParameter 子workflow 引用的ClusterWorkflowTemplate，与spec 互斥
+	ClusterWorkflowTemplateParameter = "clusterWorkflowTemplate"
+	// InheritParametersParameter 为true 时子workflow 继承父workflow 的parameters，引用模板时不生效
+	InheritParametersParameter = "inheritParameters"
+	// ParameterPrefix parameters.<key>: <value> 设置子workflow 的parameters
+	ParameterPrefix = "parameters."
+	// AttributePrefix attributes.<key>: <childKey> 把子workflow 的attribute 映射到step，没有设置时映射全部attributes
+	AttributePrefix = "attributes."
+	// ParentWorkflowLabel 子workflow 上记录父workflow 的名称
+	ParentWorkflowLabel = "parent-workflow"
+)
+
+var workflowClient client.Client
+
+// SetClient 设置workflow step 创建、查询子workflow 使用的client
+func SetClient(c client.Client) {
+	workflowClient = c
+}
+
+// Workflow 创建子workflow 并轮询其状态，回滚时删除子workflow，由子workflow 自己完成回滚
+type Workflow struct {
+	client client.Client
+}
+
+func NewWorkflow(cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (stepinterface.StepV2, error) {
+	if workflowClient == nil {
+		return nil, fmt.Errorf("client of workflow step is not set")
+	}
+	return &Workflow{client: workflowClient}, nil
+}
+
+func (w *Workflow) Run(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	child, err := newChildWorkflow(workflow, step)
+	if err != nil {
+		return stepinterface.NewStepError(err, false, false)
+	}
+	// 重试时子workflow 可能已经创建了
+	if err = w.client.Create(ctx, child); err != nil && !k8sapierrors.IsAlreadyExists(err) {
+		return stepinterface.NewStepError(err, true, false)
+	}
+	step.Status.Resource.ID = child.Name
+	step.Status.Resource.Name = child.Name
+	step.Status.Resource.Status = string(v1alpha1.WorkflowPending)
+	return nil
+}
+
+func (w *Workflow) Poll(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (bool, stepinterface.StepError) {
+	child := &v1alpha1.Workflow{}
+	if err := w.client.Get(ctx, types.NamespacedName{Namespace: step.Namespace, Name: step.Name}, child); err != nil {
+		if k8sapierrors.IsNotFound(err) {
+			// 子workflow 被删除了，重新创建
+			return false, stepinterface.NewStepError(err, true, false)
+		}
+		return false, stepinterface.NewStepError(err, true, true)
+	}
+	step.Status.Resource.Status = string(child.Status.Phase)
+	switch child.Status.Phase {
+	case v1alpha1.WorkflowSuccess:
+		mapAttributes(step, child)
+		// 子workflow 的结构化输出作为step 的outputs
+		if child.Status.Outputs != nil {
+			step.Status.Outputs = child.Status.Outputs.DeepCopy()
+		}
+		return true, nil
+	case v1alpha1.WorkflowFailed, v1alpha1.WorkflowRollBacked:
+		return false, stepinterface.NewStepError(fmt.Errorf("child workflow %s is %s: %s",
+			child.Name, child.Status.Phase, child.Status.RunError), false, false)
+	}
+	return false, nil
+}
+
+func (w *Workflow) Rollback(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	child := &v1alpha1.Workflow{}
+	if err := w.client.Get(ctx, types.NamespacedName{Namespace: step.Namespace, Name: step.Name}, child); err != nil {
+		if k8sapierrors.IsNotFound(err) {
+			return nil
+		}
+		return stepinterface.NewStepError(err, true, false)
+	}
+	if child.DeletionTimestamp.IsZero() {
+		if err := w.client.Delete(ctx, child); client.IgnoreNotFound(err) != nil {
+			return stepinterface.NewStepError(err, true, false)
+		}
+	} else if child.Status.Phase == v1alpha1.WorkflowFailed {
+		return stepinterface.NewStepError(fmt.Errorf("child workflow %s rollback failed: %s", child.Name, child.Status.RollbackError), false, false)
+	}
+	// 等待子workflow 回滚完成被删除，不计入重试次数
+	step.Status.Resource.Status = string(child.Status.Phase)
+	return stepinterface.NewStepError(fmt.Errorf("waiting for child workflow %s to roll back", child.Name), true, true)
+}
+
+func (w *Workflow) Sync(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	return nil
+}
+
+// newChildWorkflow 子workflow 与step 同名，parameters 依次来自spec、父workflow(inheritParameters) 和step 的parameters.<key>
+func newChildWorkflow(workflow *v1alpha1.Workflow, step *v1alpha1.Step) (*v1alpha1.Workflow, error) {
+	spec, err := childWorkflowSpec(step)
+	if err != nil {
+		return nil, err
+	}
+	if spec.Parameters == nil {
+		spec.Parameters = map[string]string{}
+	}
+	// 模板只接受声明过的参数，不继承父workflow 的parameters
+	if step.Spec.Parameters[InheritParametersParameter] == "true" && spec.WorkflowTemplateRef == nil {
+		for k, v := range workflow.Spec.Parameters {
+			if _, ok := spec.Parameters[k]; !ok {
+				spec.Parameters[k] = v
+			}
+		}
+	}
+	for k, v := range step.Spec.Parameters {
+		if strings.HasPrefix(k, ParameterPrefix) {
+			spec.Parameters[strings.TrimPrefix(k, ParameterPrefix)] = v
+		}
+	}
+	boolPtr := func(b bool) *bool { return &b }
+	child := &v1alpha1.Workflow{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: step.Namespace,
+			Name:      step.Name,
+			Labels: map[string]string{
+				ParentWorkflowLabel: workflow.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         v1alpha1.GroupVersion.String(),
+				Kind:               "Step",
+				Name:               step.Name,
+				UID:                step.UID,
+				BlockOwnerDeletion: boolPtr(false),
+			}},
+		},
+		Spec: spec,
+	}
+	return child, nil
+}
+
+// childWorkflowSpec 子workflow 的spec 来自spec 参数，或者引用workflowTemplate/clusterWorkflowTemplate
+func childWorkflowSpec(step *v1alpha1.Step) (v1alpha1.WorkflowSpec, error) {
+	spec := v1alpha1.WorkflowSpec{}
+	rawSpec := step.Spec.Parameters[WorkflowSpecParameter]
+	templateName := step.Spec.Parameters[WorkflowTemplateParameter]
+	clusterTemplateName := step.Spec.Parameters[ClusterWorkflowTemplateParameter]
+	refs := 0
+	for _, v := range []string{rawSpec, templateName, clusterTemplateName} {
+		if len(v) > 0 {
+			refs++
+		}
+	}
+	if refs != 1 {
+		return spec, fmt.Errorf("exactly one of parameter %s, %s and %s is required",
+			WorkflowSpecParameter, WorkflowTemplateParameter, ClusterWorkflowTemplateParameter)
+	}
+	switch {
+	case len(templateName) > 0:
+		spec.WorkflowTemplateRef = &v1alpha1.WorkflowTemplateRef{Name: templateName}
+	case len(clusterTemplateName) > 0:
+		spec.WorkflowTemplateRef = &v1alpha1.WorkflowTemplateRef{Name: clusterTemplateName, ClusterScope: true}
+	default:
+		if err := yaml.Unmarshal([]byte(rawSpec), &spec); err != nil {
+			return spec, fmt.Errorf("parameter %s is invalid: %v", WorkflowSpecParameter, err)
+		}
+	}
+	return spec, nil
+}
+
+// mapAttributes 按attributes.<key>: <childKey> 把子workflow 的attributes 映射到step，没有设置时映射全部attributes
+func mapAttributes(step *v1alpha1.Step, child *v1alpha1.Workflow) {
+	if step.Status.Attributes == nil {
+		step.Status.Attributes = map[string]string{}
+	}
+	mapped := false
+	for k, v := range step.Spec.Parameters {
+		if strings.HasPrefix(k, AttributePrefix) {
+			mapped = true
+			step.Status.Attributes[strings.TrimPrefix(k, AttributePrefix)] = child.Status.Attributes[v]
+		}
+	}
+	if mapped {
+		return
+	}
+	for k, v := range child.Status.Attributes {
+		step.Status.Attributes[k] = v
+	}
+}
@@ -0,0 +1,126 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
+)
+
+func TestWorkflowStep(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	SetClient(c)
+	defer SetClient(nil)
+
+	parent := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{Parameters: map[string]string{"env": "prod", "region": "us"}}}
+	parent.Name = "parent"
+	step := &v1alpha1.Step{Spec: v1alpha1.StepSpec{Type: "workflow", Parameters: map[string]string{
+		WorkflowSpecParameter: `
+parameters:
+  region: eu
+steps:
+- name: a
+  stepTemplate:
+    type: empty
+`,
+		InheritParametersParameter: "true",
+		"parameters.zone":          "1a",
+		"attributes.childIP":       "ip",
+	}}}
+	step.Name = "parent-sub"
+	step.Namespace = "default"
+
+	s, err := stepinterface.NewStepV2(nil, parent, step)
+	if err != nil {
+		t.Fatalf("new workflow step error: %v", err)
+	}
+	async, ok := s.(stepinterface.AsyncStep)
+	if !ok {
+		t.Fatalf("expect workflow step is async")
+	}
+	ctx := context.Background()
+	if stepErr := async.Run(ctx, parent, step); stepErr != nil {
+		t.Fatalf("run error: %v", stepErr)
+	}
+	child := &v1alpha1.Workflow{}
+	key := types.NamespacedName{Namespace: "default", Name: "parent-sub"}
+	if err = c.Get(ctx, key, child); err != nil {
+		t.Fatalf("get child workflow error: %v", err)
+	}
+	expect := map[string]string{"env": "prod", "region": "eu", "zone": "1a"}
+	for k, v := range expect {
+		if child.Spec.Parameters[k] != v {
+			t.Errorf("expect child parameter %s=%s, got %v", k, v, child.Spec.Parameters)
+		}
+	}
+	if child.Labels[ParentWorkflowLabel] != "parent" || len(child.Spec.Steps) != 1 {
+		t.Errorf("unexpected child workflow %+v", child)
+	}
+
+	// 子workflow 运行中
+	if done, stepErr := async.Poll(ctx, parent, step); done || stepErr != nil {
+		t.Fatalf("expect child running, got %v %v", done, stepErr)
+	}
+	child.Status.Phase = v1alpha1.WorkflowSuccess
+	child.Status.Attributes = map[string]string{"ip": "10.0.0.1", "other": "x"}
+	child.Status.Outputs = &runtime.RawExtension{Raw: []byte(`{"a":{"ip":"10.0.0.1"}}`)}
+	if err = c.Update(ctx, child); err != nil {
+		t.Fatalf("update child workflow error: %v", err)
+	}
+	if done, stepErr := async.Poll(ctx, parent, step); !done || stepErr != nil {
+		t.Fatalf("expect child success, got %v %v", done, stepErr)
+	}
+	if len(step.Status.Attributes) != 1 || step.Status.Attributes["childIP"] != "10.0.0.1" {
+		t.Errorf("expect attributes mapped, got %v", step.Status.Attributes)
+	}
+	if step.Status.Outputs == nil || string(step.Status.Outputs.Raw) != `{"a":{"ip":"10.0.0.1"}}` {
+		t.Errorf("expect outputs of child workflow, got %v", step.Status.Outputs)
+	}
+
+	// 回滚时删除子workflow，删除完成后回滚成功
+	if stepErr := async.Rollback(ctx, parent, step); stepErr == nil || !stepErr.Ignorable() {
+		t.Fatalf("expect waiting for child rollback, got %v", stepErr)
+	}
+	if stepErr := async.Rollback(ctx, parent, step); stepErr != nil {
+		t.Fatalf("expect rollback done, got %v", stepErr)
+	}
+}
+
+func TestNewChildWorkflowWithoutSpec(t *testing.T) {
+	step := &v1alpha1.Step{Spec: v1alpha1.StepSpec{Type: "workflow"}}
+	if _, err := newChildWorkflow(&v1alpha1.Workflow{}, step); err == nil {
+		t.Errorf("expect error without spec")
+	}
+}
+
+func TestNewChildWorkflowWithTemplate(t *testing.T) {
+	step := &v1alpha1.Step{Spec: v1alpha1.StepSpec{Type: "workflow", Parameters: map[string]string{
+		ClusterWorkflowTemplateParameter: "deploy",
+		InheritParametersParameter:       "true",
+		"parameters.env":                 "prod",
+	}}}
+	parent := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{Parameters: map[string]string{"region": "us"}}}
+	child, err := newChildWorkflow(parent, step)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	ref := child.Spec.WorkflowTemplateRef
+	if ref == nil || ref.Name != "deploy" || !ref.ClusterScope {
+		t.Errorf("expect cluster template ref, got %+v", ref)
+	}
+	// 引用模板时不继承父workflow 的parameters
+	if len(child.Spec.Parameters) != 1 || child.Spec.Parameters["env"] != "prod" {
+		t.Errorf("expect only parameters of step, got %v", child.Spec.Parameters)
+	}
+	step.Spec.Parameters[WorkflowSpecParameter] = "steps: []"
+	if _, err = newChildWorkflow(parent, step); err == nil {
+		t.Errorf("expect error with both spec and template")
+	}
+}
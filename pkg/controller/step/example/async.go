@@ -0,0 +1,62 @@
+package example
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
+	"github.com/qiankunli/workflow/pkg/options"
+	"github.com/qiankunli/workflow/pkg/utils"
+)
+
+func init() {
+	stepinterface.FactoryV2["async"] = NewAsync
+}
+
+// Async 模拟提交外部任务后轮询任务状态的异步step，不会长时间占用reconcile worker
+type Async struct {
+	*v1alpha1.Step
+}
+
+func NewAsync(cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (stepinterface.StepV2, error) {
+	return &Async{
+		Step: step,
+	}, nil
+}
+
+func (i *Async) Run(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	sleepSeconds := 10
+	if i.Step.Spec.Parameters != nil && len(i.Step.Spec.Parameters["sleepSeconds"]) > 0 {
+		sleepSeconds = utils.ToInt(i.Step.Spec.Parameters["sleepSeconds"], 10)
+	}
+	// 提交任务，记录任务句柄
+	step.Status.Resource.ID = fmt.Sprintf("%d", rand.Int())
+	step.Status.Resource.Status = "Submitted"
+	step.Status.Resource.Attributes["finishAt"] = utils.ConvertTimeToString(time.Now().Add(time.Duration(sleepSeconds) * time.Second))
+	return nil
+}
+
+func (i *Async) Poll(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (bool, stepinterface.StepError) {
+	finishAt, err := time.Parse(time.RFC3339, step.Status.Resource.Attributes["finishAt"])
+	if err != nil {
+		// 任务句柄不对，重新提交
+		return false, stepinterface.NewStepError(err, true, false)
+	}
+	if time.Now().Before(finishAt) {
+		return false, nil
+	}
+	step.Status.Resource.Status = "Finished"
+	return true, nil
+}
+
+func (i *Async) Rollback(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	step.Status.Resource.Status = "Deleted"
+	return nil
+}
+
+func (i *Async) Sync(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	return nil
+}
@@ -1,25 +1,25 @@
 package example
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
-	"time"
 
 	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
 	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
 	"github.com/qiankunli/workflow/pkg/options"
-	"github.com/qiankunli/workflow/pkg/utils"
 )
 
 func init() {
-	stepinterface.Factory["error"] = NewError
+	stepinterface.FactoryV2["error"] = NewError
 }
 
+// Error 模拟一个耗时sleepSeconds 的异步任务，第一次运行结束时失败，回滚也会失败
 type Error struct {
 	*v1alpha1.Step
 }
 
-func NewError(cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (stepinterface.Step, error) {
+func NewError(cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (stepinterface.StepV2, error) {
 	return &Error{
 		Step: step,
 	}, nil
@@ -30,32 +30,32 @@ type Data struct {
 	SleepSeconds int `json:"sleepSeconds,omitempty"`
 }
 
-func (i *Error) Run(workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+func (i *Error) Run(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	submit(step)
+	return nil
+}
 
-	sleepSeconds := 10
-	if i.Step.Spec.Parameters != nil && len(i.Step.Spec.Parameters["sleepSeconds"]) > 0 {
-		sleepSeconds = utils.ToInt(i.Step.Spec.Parameters["sleepSeconds"], 10)
+func (i *Error) Poll(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (bool, stepinterface.StepError) {
+	if !finished(step) {
+		return false, nil
 	}
-	time.Sleep(time.Duration(sleepSeconds) * time.Second)
-
-	id := fmt.Sprintf("%d", rand.Int())
+	// 第一次运行失败，重试后成功
 	if len(step.Status.Resource.ID) == 0 {
-		step.Status.Resource.ID = id
-		return stepinterface.NewCodeError("test", "run error", false, false)
+		step.Status.Resource.ID = fmt.Sprintf("%d", rand.Int())
+		step.Status.Resource.Status = "Failed"
+		return false, stepinterface.NewCodeError("test", "run error", false, false)
 	}
-	return nil
+	step.Status.Resource.Status = "Finished"
+	return true, nil
 }
-func (i *Error) Rollback(workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
-	sleepSeconds := 10
-	if i.Step.Spec.Parameters != nil && len(i.Step.Spec.Parameters["sleepSeconds"]) > 0 {
-		sleepSeconds = utils.ToInt(i.Step.Spec.Parameters["sleepSeconds"], 10)
-	}
-
-	time.Sleep(time.Duration(sleepSeconds) * time.Second)
 
+func (i *Error) Rollback(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	if err := sleep(ctx, sleepDuration(step)); err != nil {
+		return stepinterface.NewStepError(err, true, false)
+	}
 	return stepinterface.NewCodeError("test", "rollback error", false, false)
 }
 
-func (i *Error) Sync(workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+func (i *Error) Sync(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
 	return nil
 }
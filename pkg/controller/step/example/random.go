@@ -1,8 +1,10 @@
 package example
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
+	"strconv"
 	"time"
 
 	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
@@ -12,44 +14,47 @@ import (
 )
 
 func init() {
-	stepinterface.Factory["random"] = NewRandom
+	stepinterface.FactoryV2["random"] = NewRandom
 }
 
+// sleepSecondsAttribute Run 时记录在status.resource.attributes 中的任务耗时
+const sleepSecondsAttribute = "sleepSeconds"
+
+// Random 模拟一个耗时sleepSeconds 的异步任务，Run 只提交任务，Poll 等待任务结束
 type Random struct {
 	*v1alpha1.Step
 }
 
-func NewRandom(cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (stepinterface.Step, error) {
+func NewRandom(cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (stepinterface.StepV2, error) {
 	return &Random{
 		Step: step,
 	}, nil
 }
 
-func (i *Random) Run(workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
-	sleepSeconds := 10
-	if i.Step.Spec.Parameters != nil && len(i.Step.Spec.Parameters["sleepSeconds"]) > 0 {
-		sleepSeconds = utils.ToInt(i.Step.Spec.Parameters["sleepSeconds"], 10)
-	}
-
-	time.Sleep(time.Duration(sleepSeconds) * time.Second)
+func (i *Random) Run(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	submit(step)
+	return nil
+}
 
+func (i *Random) Poll(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (bool, stepinterface.StepError) {
+	if !finished(step) {
+		return false, nil
+	}
 	id := fmt.Sprintf("%d", rand.Int())
 	step.Status.Resource.ID = id
-	return nil
+	step.Status.Resource.Status = "Finished"
+	return true, nil
 }
-func (i *Random) Rollback(workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
 
-	sleepSeconds := 10
-	if i.Step.Spec.Parameters != nil && len(i.Step.Spec.Parameters["sleepSeconds"]) > 0 {
-		sleepSeconds = utils.ToInt(i.Step.Spec.Parameters["sleepSeconds"], 10)
+func (i *Random) Rollback(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+	if err := sleep(ctx, sleepDuration(step)); err != nil {
+		return stepinterface.NewStepError(err, true, false)
 	}
-
-	time.Sleep(time.Duration(sleepSeconds) * time.Second)
 	return nil
 
 }
 
-func (i *Random) Sync(workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
+func (i *Random) Sync(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinterface.StepError {
 	// 更新step 可能引起workflow 更新step 冲突
 	if rand.Int()%2 == 0 {
 		id := fmt.Sprintf("%d", rand.Int())
@@ -58,3 +63,39 @@ func (i *Random) Sync(workflow *v1alpha1.Workflow, step *v1alpha1.Step) stepinte
 	}
 	return nil
 }
+
+// sleepDuration parameters 中sleepSeconds 指定的任务耗时，默认10s
+func sleepDuration(step *v1alpha1.Step) time.Duration {
+	sleepSeconds := 10
+	if step.Spec.Parameters != nil && len(step.Spec.Parameters["sleepSeconds"]) > 0 {
+		sleepSeconds = utils.ToInt(step.Spec.Parameters["sleepSeconds"], 10)
+	}
+	return time.Duration(sleepSeconds) * time.Second
+}
+
+// submit 模拟提交任务，记录任务的耗时，提交时间由controller 记录在status.submittedAt 中
+func submit(step *v1alpha1.Step) {
+	if step.Status.Resource.Attributes == nil {
+		step.Status.Resource.Attributes = map[string]string{}
+	}
+	step.Status.Resource.Status = "Submitted"
+	step.Status.Resource.Attributes[sleepSecondsAttribute] = strconv.Itoa(int(sleepDuration(step) / time.Second))
+}
+
+// finished 任务从提交开始是否已经执行了记录的耗时
+func finished(step *v1alpha1.Step) bool {
+	sleepSeconds := utils.ToInt(step.Status.Resource.Attributes[sleepSecondsAttribute], 10)
+	return time.Since(step.Status.SubmittedAt.Time) >= time.Duration(sleepSeconds)*time.Second
+}
+
+// sleep 模拟任务执行的耗时，ctx 被cancel 时提前返回
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,63 @@
+package example
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
+)
+
+// submitTestStep 提交sleepSeconds 为60 的任务
+func submitTestStep(t *testing.T, stepType string) (stepinterface.AsyncStep, *v1alpha1.Step) {
+	step := &v1alpha1.Step{Spec: v1alpha1.StepSpec{Type: stepType, Parameters: map[string]string{"sleepSeconds": "60"}}}
+	s, err := stepinterface.NewStepV2(nil, &v1alpha1.Workflow{}, step)
+	if err != nil {
+		t.Fatalf("new step error: %v", err)
+	}
+	async, ok := s.(stepinterface.AsyncStep)
+	if !ok {
+		t.Fatalf("expect %s to be an async step", stepType)
+	}
+	if stepErr := async.Run(context.Background(), &v1alpha1.Workflow{}, step); stepErr != nil {
+		t.Fatalf("run error: %v", stepErr)
+	}
+	step.Status.SubmittedAt = metav1.Now()
+	return async, step
+}
+
+func TestRandomPoll(t *testing.T) {
+	s, step := submitTestStep(t, "random")
+	if done, stepErr := s.Poll(context.Background(), &v1alpha1.Workflow{}, step); done || stepErr != nil {
+		t.Fatalf("expect running, got %v %v", done, stepErr)
+	}
+	step.Status.SubmittedAt = metav1.NewTime(time.Now().Add(-time.Minute))
+	if done, stepErr := s.Poll(context.Background(), &v1alpha1.Workflow{}, step); !done || stepErr != nil {
+		t.Fatalf("expect finished, got %v %v", done, stepErr)
+	}
+	if len(step.Status.Resource.ID) == 0 {
+		t.Errorf("expect resource id set")
+	}
+}
+
+func TestErrorPoll(t *testing.T) {
+	s, step := submitTestStep(t, "error")
+	if done, stepErr := s.Poll(context.Background(), &v1alpha1.Workflow{}, step); done || stepErr != nil {
+		t.Fatalf("expect running, got %v %v", done, stepErr)
+	}
+	// 第一次运行结束时失败
+	step.Status.SubmittedAt = metav1.NewTime(time.Now().Add(-time.Minute))
+	if done, stepErr := s.Poll(context.Background(), &v1alpha1.Workflow{}, step); done || stepErr == nil || stepErr.Retryable() {
+		t.Fatalf("expect run error, got %v %v", done, stepErr)
+	}
+	// 重试后成功
+	if stepErr := s.Run(context.Background(), &v1alpha1.Workflow{}, step); stepErr != nil {
+		t.Fatalf("run error: %v", stepErr)
+	}
+	if done, stepErr := s.Poll(context.Background(), &v1alpha1.Workflow{}, step); !done || stepErr != nil {
+		t.Fatalf("expect finished, got %v %v", done, stepErr)
+	}
+}
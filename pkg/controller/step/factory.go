@@ -10,8 +10,13 @@ import (
 
 type NewStepFunc func(cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (Step, error)
 
+type NewStepV2Func func(cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (StepV2, error)
+
 var Factory = map[string]NewStepFunc{}
 
+// FactoryV2 registers context aware steps, it takes precedence over Factory
+var FactoryV2 = map[string]NewStepV2Func{}
+
 func NewStep(cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (Step, error) {
 	newFunc, ok := Factory[step.Spec.Type]
 	if !ok {
@@ -21,3 +26,15 @@ func NewStep(cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.St
 	}
 	return newFunc(cfg, workflow, step)
 }
+
+// NewStepV2 instantiates a context aware step, steps registered in Factory are adapted to StepV2
+func NewStepV2(cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (StepV2, error) {
+	if newFunc, ok := FactoryV2[step.Spec.Type]; ok {
+		return newFunc(cfg, workflow, step)
+	}
+	s, err := NewStep(cfg, workflow, step)
+	if err != nil {
+		return nil, err
+	}
+	return AdaptStep(s), nil
+}
@@ -1,11 +1,58 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"testing"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/options"
 )
 
 func TestNewStep(t *testing.T) {
 	_, ok := Factory["random"]
 	fmt.Println(ok)
 }
+
+type fakeStep struct {
+	runCount int
+}
+
+func (f *fakeStep) Run(workflow *v1alpha1.Workflow, step *v1alpha1.Step) StepError {
+	f.runCount++
+	return nil
+}
+func (f *fakeStep) Rollback(workflow *v1alpha1.Workflow, step *v1alpha1.Step) StepError {
+	return NewCodeError("fake", "rollback error", true, false)
+}
+func (f *fakeStep) Sync(workflow *v1alpha1.Workflow, step *v1alpha1.Step) StepError {
+	return nil
+}
+
+func TestNewStepV2(t *testing.T) {
+	fake := &fakeStep{}
+	Factory["fake"] = func(cfg *options.Config, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (Step, error) {
+		return fake, nil
+	}
+	defer delete(Factory, "fake")
+
+	step := &v1alpha1.Step{Spec: v1alpha1.StepSpec{Type: "fake"}}
+	s, err := NewStepV2(nil, &v1alpha1.Workflow{}, step)
+	if err != nil {
+		t.Fatalf("new step v2 error: %v", err)
+	}
+	if stepErr := s.Run(context.Background(), &v1alpha1.Workflow{}, step); stepErr != nil {
+		t.Fatalf("run error: %v", stepErr)
+	}
+	if fake.runCount != 1 {
+		t.Fatalf("expect run count 1, got %d", fake.runCount)
+	}
+	if stepErr := s.Rollback(context.Background(), &v1alpha1.Workflow{}, step); stepErr == nil || !stepErr.Retryable() {
+		t.Fatalf("expect retryable rollback error, got %v", stepErr)
+	}
+
+	step.Spec.Type = "not-exist"
+	if _, err = NewStepV2(nil, &v1alpha1.Workflow{}, step); err == nil {
+		t.Fatalf("expect error for unknown step type")
+	}
+}
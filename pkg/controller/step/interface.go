@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
@@ -44,6 +45,22 @@ func (s *codeError) Retryable() bool {
 func (s *codeError) Ignorable() bool {
 	return s.ignorable
 }
+func (s *codeError) Code() string {
+	return s.code
+}
+
+// CodedError is implemented by StepError carrying an error code, which can be matched by RetryPolicy.Rules
+type CodedError interface {
+	Code() string
+}
+
+// ErrorCode returns the code of err, empty if err has no code
+func ErrorCode(err StepError) string {
+	if codedErr, ok := err.(CodedError); ok {
+		return codedErr.Code()
+	}
+	return ""
+}
 
 func NewCodeError(code, message string, retryable, ignorable bool) StepError {
 	return &codeError{
@@ -67,3 +84,46 @@ type Step interface {
 	Rollback(workflow *v1alpha1.Workflow, step *v1alpha1.Step) StepError
 	Sync(workflow *v1alpha1.Workflow, step *v1alpha1.Step) StepError // 或者叫healthcheck
 }
+
+// StepV2 is the context aware version of Step, ctx is cancelled when the controller shuts down,
+// the workflow is deleted or the step timeout is reached, step implementations should return as soon as possible then.
+type StepV2 interface {
+	Run(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) StepError
+	Rollback(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) StepError
+	Sync(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) StepError
+}
+
+// AsyncStep is implemented by StepV2 whose Run only submits an external job and returns immediately.
+// Run should save the job handle in step.Status.Resource, a nil StepError means the job is submitted,
+// then the controller calls Poll every PollPeriodSeconds until the job is done:
+//   - done is true: the step is Success
+//   - retryable and ignorable error: poll failed, the job is polled again later
+//   - retryable error: the job failed, it is submitted again by Run after the run retry period
+//   - not retryable error: the step is RollingBack
+//
+// RetryPolicy rules matching a Poll error only decide whether it is counted or fails the step,
+// a poll error is still polled again and a job failure is still submitted again.
+type AsyncStep interface {
+	StepV2
+	Poll(ctx context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) (bool, StepError)
+}
+
+// stepAdapter adapts Step to StepV2, ctx is ignored
+type stepAdapter struct {
+	step Step
+}
+
+func (a *stepAdapter) Run(_ context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) StepError {
+	return a.step.Run(workflow, step)
+}
+func (a *stepAdapter) Rollback(_ context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) StepError {
+	return a.step.Rollback(workflow, step)
+}
+func (a *stepAdapter) Sync(_ context.Context, workflow *v1alpha1.Workflow, step *v1alpha1.Step) StepError {
+	return a.step.Sync(workflow, step)
+}
+
+// AdaptStep wraps a Step as StepV2 so that existing step implementations keep working
+func AdaptStep(s Step) StepV2 {
+	return &stepAdapter{step: s}
+}
@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+// GetOutput reads the value at path from the structured outputs of the step, path uses the gjson syntax such as
+// subnets.0.id. The result does not exist if the step has no outputs
+func GetOutput(step *v1alpha1.Step, path string) gjson.Result {
+	if step.Status.Outputs == nil {
+		return gjson.Result{}
+	}
+	return gjson.GetBytes(step.Status.Outputs.Raw, path)
+}
+
+// SetOutput writes value to path of the structured outputs of the step, value is encoded as JSON
+func SetOutput(step *v1alpha1.Step, path string, value interface{}) error {
+	raw, err := sjson.SetBytes(outputsOf(step), path, value)
+	if err != nil {
+		return err
+	}
+	step.Status.Outputs = &runtime.RawExtension{Raw: raw}
+	return nil
+}
+
+// SetRawOutput writes the raw JSON value to path of the structured outputs of the step
+func SetRawOutput(step *v1alpha1.Step, path string, value []byte) error {
+	raw, err := sjson.SetRawBytes(outputsOf(step), path, value)
+	if err != nil {
+		return err
+	}
+	step.Status.Outputs = &runtime.RawExtension{Raw: raw}
+	return nil
+}
+
+// EscapePath escapes the characters of a key with special meaning in gjson/sjson paths, such as the dot in a step name
+func EscapePath(key string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ".", `\.`, "*", `\*`, "?", `\?`)
+	return replacer.Replace(key)
+}
+
+func outputsOf(step *v1alpha1.Step) []byte {
+	if step.Status.Outputs == nil || len(step.Status.Outputs.Raw) == 0 {
+		return []byte("{}")
+	}
+	return step.Status.Outputs.Raw
+}
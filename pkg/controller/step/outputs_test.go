@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+func TestOutputs(t *testing.T) {
+	step := &v1alpha1.Step{}
+	if GetOutput(step, "vpc").Exists() {
+		t.Fatalf("expect no output")
+	}
+	if err := SetOutput(step, "vpc.id", "vpc-1"); err != nil {
+		t.Fatalf("set output error: %v", err)
+	}
+	if err := SetOutput(step, "subnets", []map[string]string{{"id": "subnet-1"}, {"id": "subnet-2"}}); err != nil {
+		t.Fatalf("set output error: %v", err)
+	}
+	if err := SetRawOutput(step, "tags", []byte(`{"env":"prod"}`)); err != nil {
+		t.Fatalf("set raw output error: %v", err)
+	}
+	if v := GetOutput(step, "subnets.1.id").String(); v != "subnet-2" {
+		t.Errorf("expect subnet-2, got %s", v)
+	}
+	if v := GetOutput(step, "vpc.id").String(); v != "vpc-1" {
+		t.Errorf("expect vpc-1, got %s", v)
+	}
+	if v := GetOutput(step, "tags").Raw; v != `{"env":"prod"}` {
+		t.Errorf("expect raw tags, got %s", v)
+	}
+	if err := SetOutput(step, "a\\.b", 1); err != nil || GetOutput(step, EscapePath("a.b")).Int() != 1 {
+		t.Errorf("expect escaped key, got %s", step.Status.Outputs.Raw)
+	}
+}
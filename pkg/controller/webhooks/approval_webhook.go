@@ -0,0 +1,115 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/tidwall/sjson"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/qiankunli/workflow/pkg/constants"
+	"github.com/qiankunli/workflow/pkg/controller/manager"
+	"github.com/qiankunli/workflow/pkg/controller/step/common"
+)
+
+// approvalWebhookPath step、workflow 共用的记录审批人的mutating webhook
+const approvalWebhookPath = "/mutate-workflow-example-com-v1alpha1-approval"
+
+type approvalWebhook struct {
+	log logr.Logger
+}
+
+// RegisterApprovalWebhook ...
+func RegisterApprovalWebhook(mgr ctrl.Manager, controllerCtx *manager.ControllerContext) error {
+	const name = "approval-webhook"
+
+	w := &approvalWebhook{
+		log: ctrl.LoggerFrom(context.Background()).WithName(name),
+	}
+	mgr.GetWebhookServer().Register(approvalWebhookPath, &webhook.Admission{Handler: w})
+	w.log.Info("succeeded to set up with manager")
+	return nil
+}
+
+// Handle 审批annotation 变化时把请求的用户记录为审批人，审批人不能直接修改
+func (w *approvalWebhook) Handle(ctx context.Context, req admission.Request) admission.Response {
+	obj := &metav1.PartialObjectMetadata{}
+	if err := json.Unmarshal(req.Object.Raw, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	oldObj := &metav1.PartialObjectMetadata{}
+	if len(req.OldObject.Raw) > 0 {
+		if err := json.Unmarshal(req.OldObject.Raw, oldObj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+	annotations, changed, err := approverAnnotations(oldObj.Annotations, obj.Annotations, req.UserInfo.Username)
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+	if !changed {
+		return admission.Allowed("")
+	}
+	raw, err := sjson.SetBytes(req.Object.Raw, "metadata.annotations", annotations)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, raw)
+}
+
+// approverAnnotations 按审批annotation 的变化设置审批人，返回新的annotations 以及是否有修改
+func approverAnnotations(oldAnnotations, annotations map[string]string, user string) (map[string]string, bool, error) {
+	// 审批annotation 与审批人annotation 的对应关系
+	approvers := map[string]string{}
+	for _, m := range []map[string]string{oldAnnotations, annotations} {
+		for k := range m {
+			switch {
+			case k == constants.AnnotationApproval || k == constants.AnnotationApprover:
+				approvers[constants.AnnotationApproval] = constants.AnnotationApprover
+			case strings.HasPrefix(k, constants.ApprovalAnnotationPrefix):
+				approvers[k] = constants.ApproverAnnotationPrefix + strings.TrimPrefix(k, constants.ApprovalAnnotationPrefix)
+			case strings.HasPrefix(k, constants.ApproverAnnotationPrefix):
+				approvers[constants.ApprovalAnnotationPrefix+strings.TrimPrefix(k, constants.ApproverAnnotationPrefix)] = k
+			}
+		}
+	}
+	ret := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		ret[k] = v
+	}
+	changed := false
+	set := func(k, v string, ok bool) {
+		if current, exists := ret[k]; exists == ok && current == v {
+			return
+		}
+		changed = true
+		if ok {
+			ret[k] = v
+		} else {
+			delete(ret, k)
+		}
+	}
+	for approvalKey, approverKey := range approvers {
+		outcome, ok := annotations[approvalKey]
+		if ok && !common.IsApprovalOutcome(outcome) {
+			return nil, false, fmt.Errorf("annotation %s must be %s or %s", approvalKey, common.ApprovalApproved, common.ApprovalRejected)
+		}
+		oldOutcome, oldOk := oldAnnotations[approvalKey]
+		if ok != oldOk || outcome != oldOutcome {
+			// 审批结果变化，记录本次请求的用户
+			set(approverKey, user, ok)
+			continue
+		}
+		// 审批结果没有变化，审批人保持不变
+		oldApprover, oldApproverOk := oldAnnotations[approverKey]
+		set(approverKey, oldApprover, oldApproverOk)
+	}
+	return ret, changed, nil
+}
@@ -0,0 +1,64 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/qiankunli/workflow/pkg/constants"
+)
+
+func newApprovalRequest(user, oldObj, obj string) admission.Request {
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		UserInfo: authenticationv1.UserInfo{Username: user},
+		Object:   runtime.RawExtension{Raw: []byte(obj)},
+	}}
+	if len(oldObj) > 0 {
+		req.OldObject = runtime.RawExtension{Raw: []byte(oldObj)}
+	}
+	return req
+}
+
+func TestApprovalWebhook(t *testing.T) {
+	w := &approvalWebhook{log: logr.Discard()}
+	ctx := context.Background()
+
+	resp := w.Handle(ctx, newApprovalRequest("alice", `{"metadata":{"name":"a"}}`,
+		`{"metadata":{"name":"a","annotations":{"approval.workflow.example.com/gate":"approved"}}}`))
+	if !resp.Allowed || len(resp.Patches) != 1 || resp.Patches[0].Path != "/metadata/annotations/approver.workflow.example.com~1gate" || resp.Patches[0].Value != "alice" {
+		t.Errorf("expect approver alice added, got %+v", resp)
+	}
+
+	// 不能直接修改审批人
+	resp = w.Handle(ctx, newApprovalRequest("bob",
+		`{"metadata":{"annotations":{"workflow.example.com/approval":"approved","workflow.example.com/approver":"alice"}}}`,
+		`{"metadata":{"annotations":{"workflow.example.com/approval":"approved","workflow.example.com/approver":"bob"}}}`))
+	if !resp.Allowed || len(resp.Patches) != 1 || resp.Patches[0].Value != "alice" {
+		t.Errorf("expect approver kept alice, got %+v", resp)
+	}
+
+	resp = w.Handle(ctx, newApprovalRequest("bob", "", `{"metadata":{"annotations":{"workflow.example.com/approval":"yes"}}}`))
+	if resp.Allowed {
+		t.Errorf("expect invalid approval denied")
+	}
+
+	resp = w.Handle(ctx, newApprovalRequest("bob", "", `{"metadata":{"name":"a"}}`))
+	if !resp.Allowed || len(resp.Patches) != 0 {
+		t.Errorf("expect allowed without patch, got %+v", resp)
+	}
+}
+
+func TestApproverAnnotationsRemoved(t *testing.T) {
+	annotations, changed, err := approverAnnotations(
+		map[string]string{constants.AnnotationApproval: "approved", constants.AnnotationApprover: "alice"},
+		map[string]string{constants.AnnotationApprover: "alice"}, "bob")
+	// 撤销审批时同时删除审批人
+	if err != nil || !changed || len(annotations) != 0 {
+		t.Errorf("expect approver removed, got %v %v %v", annotations, changed, err)
+	}
+}
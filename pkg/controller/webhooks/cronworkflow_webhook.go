@@ -0,0 +1,96 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/controller/manager"
+	controller2 "github.com/qiankunli/workflow/pkg/options/controller"
+	"github.com/qiankunli/workflow/pkg/schedule"
+)
+
+// cronWorkflowNameSuffix 创建的workflow 名称为 <cronWorkflow>-<调度时间的unix 分钟数>，按8位数字校验名称长度
+const cronWorkflowNameSuffix = "-00000000"
+
+var concurrencyPolicies = []string{
+	string(v1alpha1.AllowConcurrent), string(v1alpha1.ForbidConcurrent), string(v1alpha1.ReplaceConcurrent),
+}
+
+type cronWorkflowWebhook struct {
+	config *controller2.Config
+	log    logr.Logger
+}
+
+// RegisterCronWorkflowWebhook ...
+func RegisterCronWorkflowWebhook(mgr ctrl.Manager, controllerCtx *manager.ControllerContext) error {
+	const name = "cronworkflow-webhook"
+
+	w := &cronWorkflowWebhook{
+		config: controllerCtx.Config.ControllerConfig,
+		log:    ctrl.LoggerFrom(context.Background()).WithName(name),
+	}
+	err := ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.CronWorkflow{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+	if err != nil {
+		return fmt.Errorf("failed to set up with manager: %w", err)
+	}
+	w.log.Info("succeeded to set up with manager")
+	return nil
+}
+
+// Default ...
+func (w *cronWorkflowWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	cronWorkflow, ok := obj.(*v1alpha1.CronWorkflow)
+	if !ok {
+		return fmt.Errorf("expected a CronWorkflow but got a %T", obj)
+	}
+	if cronWorkflow.Spec.ConcurrencyPolicy == "" {
+		cronWorkflow.Spec.ConcurrencyPolicy = v1alpha1.AllowConcurrent
+	}
+	defaultWorkflowSpec(&cronWorkflow.Spec.WorkflowSpec)
+	return nil
+}
+
+// ValidateCreate ...
+func (w *cronWorkflowWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	cronWorkflow, ok := obj.(*v1alpha1.CronWorkflow)
+	if !ok {
+		return fmt.Errorf("expected a CronWorkflow but got a %T", obj)
+	}
+	allErrs := validateCronWorkflowSpec(cronWorkflow.Name, &cronWorkflow.Spec, w.config, field.NewPath("spec"))
+	return toInvalidError("CronWorkflow", cronWorkflow.Name, allErrs)
+}
+
+// ValidateUpdate spec 的修改只影响之后创建的workflow
+func (w *cronWorkflowWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	return w.ValidateCreate(ctx, newObj)
+}
+
+// ValidateDelete ...
+func (w *cronWorkflowWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+func validateCronWorkflowSpec(name string, spec *v1alpha1.CronWorkflowSpec, cfg *controller2.Config, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Schedule == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("schedule"), ""))
+	} else if _, err := schedule.Parse(spec.Schedule, spec.Timezone); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("schedule"), spec.Schedule, err.Error()))
+	}
+	switch spec.ConcurrencyPolicy {
+	case "", v1alpha1.AllowConcurrent, v1alpha1.ForbidConcurrent, v1alpha1.ReplaceConcurrent:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("concurrencyPolicy"), spec.ConcurrencyPolicy, concurrencyPolicies))
+	}
+	return append(allErrs, validateWorkflowSpec(name+cronWorkflowNameSuffix, &spec.WorkflowSpec, cfg, fldPath.Child("workflowSpec"))...)
+}
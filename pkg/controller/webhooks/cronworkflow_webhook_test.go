@@ -0,0 +1,78 @@
+package webhooks
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	controller2 "github.com/qiankunli/workflow/pkg/options/controller"
+)
+
+func TestValidateCronWorkflow(t *testing.T) {
+	w := &cronWorkflowWebhook{
+		config: &controller2.Config{
+			Steps: []controller2.StepConfig{{Kind: "empty"}},
+		},
+		log: logr.Discard(),
+	}
+	newCronWorkflow := func(schedule, timezone string, policy v1alpha1.ConcurrencyPolicy) *v1alpha1.CronWorkflow {
+		cronWorkflow := &v1alpha1.CronWorkflow{Spec: v1alpha1.CronWorkflowSpec{
+			Schedule:          schedule,
+			Timezone:          timezone,
+			ConcurrencyPolicy: policy,
+			WorkflowSpec:      newTestWorkflow(newTestStep("a")).Spec,
+		}}
+		cronWorkflow.Name = "nightly"
+		return cronWorkflow
+	}
+	cases := []struct {
+		name         string
+		cronWorkflow *v1alpha1.CronWorkflow
+		// 为空表示校验通过
+		expectErr string
+	}{
+		{
+			name:         "valid",
+			cronWorkflow: newCronWorkflow("0 2 * * *", "Asia/Shanghai", v1alpha1.ForbidConcurrent),
+		},
+		{
+			name:         "invalid schedule",
+			cronWorkflow: newCronWorkflow("0 2 * *", "", v1alpha1.AllowConcurrent),
+			expectErr:    "spec.schedule: Invalid value",
+		},
+		{
+			name:         "unknown timezone",
+			cronWorkflow: newCronWorkflow("0 2 * * *", "Mars/Olympus", v1alpha1.AllowConcurrent),
+			expectErr:    "unknown timezone",
+		},
+		{
+			name:         "unsupported concurrency policy",
+			cronWorkflow: newCronWorkflow("0 2 * * *", "", "Queue"),
+			expectErr:    "spec.concurrencyPolicy: Unsupported value",
+		},
+		{
+			name: "invalid workflow spec",
+			cronWorkflow: func() *v1alpha1.CronWorkflow {
+				cronWorkflow := newCronWorkflow("0 2 * * *", "", v1alpha1.AllowConcurrent)
+				cronWorkflow.Spec.WorkflowSpec.Steps = append(cronWorkflow.Spec.WorkflowSpec.Steps, newTestStep("a"))
+				return cronWorkflow
+			}(),
+			expectErr: "spec.workflowSpec.steps[1].name: Duplicate value",
+		},
+	}
+	for _, c := range cases {
+		err := w.ValidateCreate(context.Background(), c.cronWorkflow)
+		if c.expectErr == "" {
+			if err != nil {
+				t.Errorf("%s: expect no error, got %v", c.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.expectErr) {
+			t.Errorf("%s: expect error contains %q, got %v", c.name, c.expectErr, err)
+		}
+	}
+}
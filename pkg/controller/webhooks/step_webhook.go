@@ -0,0 +1,103 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/controller/manager"
+	controller2 "github.com/qiankunli/workflow/pkg/options/controller"
+)
+
+type stepWebhook struct {
+	config *controller2.Config
+	log    logr.Logger
+}
+
+// RegisterStepWebhook ...
+func RegisterStepWebhook(mgr ctrl.Manager, controllerCtx *manager.ControllerContext) error {
+	const name = "step-webhook"
+
+	w := &stepWebhook{
+		config: controllerCtx.Config.ControllerConfig,
+		log:    ctrl.LoggerFrom(context.Background()).WithName(name),
+	}
+	err := ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.Step{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+	if err != nil {
+		return fmt.Errorf("failed to set up with manager: %w", err)
+	}
+	w.log.Info("succeeded to set up with manager")
+	return nil
+}
+
+// Default ...
+func (w *stepWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	step, ok := obj.(*v1alpha1.Step)
+	if !ok {
+		return fmt.Errorf("expected a Step but got a %T", obj)
+	}
+	defaultStepSpec(&step.Spec)
+	return nil
+}
+
+// ValidateCreate ...
+func (w *stepWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	step, ok := obj.(*v1alpha1.Step)
+	if !ok {
+		return fmt.Errorf("expected a Step but got a %T", obj)
+	}
+	allErrs := validateStepSpec(&step.Spec, w.config, field.NewPath("spec"))
+	return toInvalidError("Step", step.Name, allErrs)
+}
+
+// ValidateUpdate ...
+func (w *stepWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	oldStep, ok := oldObj.(*v1alpha1.Step)
+	if !ok {
+		return fmt.Errorf("expected a Step but got a %T", oldObj)
+	}
+	step, ok := newObj.(*v1alpha1.Step)
+	if !ok {
+		return fmt.Errorf("expected a Step but got a %T", newObj)
+	}
+	if !step.DeletionTimestamp.IsZero() || apiequality.Semantic.DeepEqual(oldStep.Spec, step.Spec) {
+		return nil
+	}
+	allErrs := validateStepSpec(&step.Spec, w.config, field.NewPath("spec"))
+	allErrs = append(allErrs, validateStepSpecUpdate(oldStep, step, field.NewPath("spec"))...)
+	return toInvalidError("Step", step.Name, allErrs)
+}
+
+// ValidateDelete ...
+func (w *stepWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+// validateStepSpecUpdate step 开始运行后type、parameters、parametersFrom 不能修改，重试、超时等配置可以调整
+func validateStepSpecUpdate(oldStep, step *v1alpha1.Step, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if oldStep.Status.Phase == "" || oldStep.Status.Phase == v1alpha1.StepPending {
+		return allErrs
+	}
+	const msg = "field is immutable after step started"
+	if oldStep.Spec.Type != step.Spec.Type {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("type"), msg))
+	}
+	if !apiequality.Semantic.DeepEqual(oldStep.Spec.Parameters, step.Spec.Parameters) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("parameters"), msg))
+	}
+	if !apiequality.Semantic.DeepEqual(oldStep.Spec.ParametersFrom, step.Spec.ParametersFrom) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("parametersFrom"), msg))
+	}
+	return allErrs
+}
@@ -0,0 +1,351 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	stepinterface "github.com/qiankunli/workflow/pkg/controller/step"
+	"github.com/qiankunli/workflow/pkg/dag"
+	"github.com/qiankunli/workflow/pkg/expression"
+	controller2 "github.com/qiankunli/workflow/pkg/options/controller"
+)
+
+const defaultQueue = "default"
+
+var stepPhases = []string{
+	string(v1alpha1.StepPending), string(v1alpha1.StepRunning), string(v1alpha1.StepSuccess),
+	string(v1alpha1.StepRollingBack), string(v1alpha1.StepRollBacked), string(v1alpha1.StepFailed),
+	string(v1alpha1.StepSkipped),
+}
+
+var dependModes = []string{string(v1alpha1.DependAll), string(v1alpha1.DependAny), string(v1alpha1.DependExpression)}
+
+// defaultStepSpec 与crd 中的默认值保持一致
+func defaultStepSpec(spec *v1alpha1.StepSpec) {
+	if spec.RollbackPolicy == "" {
+		spec.RollbackPolicy = v1alpha1.PreserveOnFailure
+	}
+	if spec.RetryPolicy.Backoff == "" {
+		spec.RetryPolicy.Backoff = v1alpha1.FixedBackoff
+	}
+	if spec.PollPeriodSeconds <= 0 {
+		spec.PollPeriodSeconds = 10
+	}
+}
+
+// defaultWorkflowSpec 补全queue、rollbackPolicy、dependMode，dependOn 没有指定phase 时默认依赖step 成功
+func defaultWorkflowSpec(spec *v1alpha1.WorkflowSpec) {
+	if spec.Queue == "" {
+		spec.Queue = defaultQueue
+	}
+	if spec.RollbackPolicy == "" {
+		spec.RollbackPolicy = v1alpha1.PreserveOnFailure
+	}
+	for i := range spec.Steps {
+		ws := &spec.Steps[i]
+		if ws.DependMode == "" {
+			ws.DependMode = v1alpha1.DependAll
+		}
+		for j := range ws.DependOns {
+			if ws.DependOns[j].Phase == "" {
+				ws.DependOns[j].Phase = v1alpha1.StepSuccess
+			}
+		}
+		defaultStepSpec(&ws.StepTemplate)
+	}
+}
+
+func validateWorkflowSpec(name string, spec *v1alpha1.WorkflowSpec, cfg *controller2.Config, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Queue == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("queue"), ""))
+	}
+	stepsPath := fldPath.Child("steps")
+	if ref := spec.WorkflowTemplateRef; ref != nil {
+		if ref.Name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("workflowTemplateRef", "name"), ""))
+		}
+		// steps 来自模板，parameters 在controller 解析模板时校验
+		if len(spec.Steps) > 0 {
+			allErrs = append(allErrs, field.Forbidden(stepsPath, "steps and workflowTemplateRef are mutually exclusive"))
+		}
+		return allErrs
+	}
+	stepNames := map[string]bool{}
+	for i, ws := range spec.Steps {
+		namePath := stepsPath.Index(i).Child("name")
+		if ws.Name == "" {
+			allErrs = append(allErrs, field.Required(namePath, ""))
+			continue
+		}
+		if stepNames[ws.Name] {
+			allErrs = append(allErrs, field.Duplicate(namePath, ws.Name))
+			continue
+		}
+		stepNames[ws.Name] = true
+		// step 对象的名称为 <workflow>-<step>
+		for _, msg := range validation.IsDNS1123Subdomain(fmt.Sprintf("%s-%s", name, ws.Name)) {
+			allErrs = append(allErrs, field.Invalid(namePath, ws.Name, msg))
+		}
+	}
+	for i, ws := range spec.Steps {
+		stepPath := stepsPath.Index(i)
+		for j, dependOn := range ws.DependOns {
+			dependOnPath := stepPath.Child("dependOns").Index(j)
+			if dependOn.Name == ws.Name {
+				allErrs = append(allErrs, field.Invalid(dependOnPath.Child("name"), dependOn.Name, "step can not depend on itself"))
+			} else if !stepNames[dependOn.Name] {
+				allErrs = append(allErrs, field.NotFound(dependOnPath.Child("name"), dependOn.Name))
+			}
+			if !isStepPhase(dependOn.Phase) {
+				allErrs = append(allErrs, field.NotSupported(dependOnPath.Child("phase"), dependOn.Phase, stepPhases))
+			}
+		}
+		if len(ws.When) > 0 {
+			if err := expression.Validate(ws.When); err != nil {
+				allErrs = append(allErrs, field.Invalid(stepPath.Child("when"), ws.When, err.Error()))
+			}
+		}
+		switch ws.DependMode {
+		case "", v1alpha1.DependAll, v1alpha1.DependAny:
+			if len(ws.DependExpression) > 0 {
+				allErrs = append(allErrs, field.Forbidden(stepPath.Child("dependExpression"), "dependExpression is only allowed when dependMode is Expression"))
+			}
+		case v1alpha1.DependExpression:
+			if err := dag.ValidateDependExpression(ws); err != nil {
+				allErrs = append(allErrs, field.Invalid(stepPath.Child("dependExpression"), ws.DependExpression, err.Error()))
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(stepPath.Child("dependMode"), ws.DependMode, dependModes))
+		}
+		allErrs = append(allErrs, validateItems(ws, spec.Parameters, stepPath)...)
+		allErrs = append(allErrs, validateOutputs(ws, stepPath)...)
+		allErrs = append(allErrs, validateParameterTemplates(ws, spec, stepPath.Child("stepTemplate", "parameters"))...)
+		allErrs = append(allErrs, validateStepSpec(&ws.StepTemplate, cfg, stepPath.Child("stepTemplate"))...)
+	}
+	// 多个step 导出到同一个workflow attribute
+	exported := map[string]bool{}
+	for i, ws := range spec.Steps {
+		for k := range ws.ExportAttributes {
+			if exported[k] {
+				allErrs = append(allErrs, field.Duplicate(stepsPath.Index(i).Child("exportAttributes"), k))
+			}
+			exported[k] = true
+		}
+	}
+	if cycle := dag.FindCycle(spec.Steps); len(cycle) > 0 {
+		allErrs = append(allErrs, field.Invalid(stepsPath, strings.Join(cycle, " -> "), "dependOns must not contain a cycle"))
+		return allErrs
+	}
+	// 不存在的step、依赖自身在上面已经校验过
+	stepIndex := map[string]int{}
+	for i, ws := range spec.Steps {
+		stepIndex[ws.Name] = i
+	}
+	for _, u := range dag.FindUnsatisfiable(spec.Steps, nil) {
+		if u.DependOn.Name == u.Step || !stepNames[u.DependOn.Name] || !isStepPhase(u.DependOn.Phase) {
+			continue
+		}
+		allErrs = append(allErrs, field.Invalid(stepsPath.Index(stepIndex[u.Step]).Child("dependOns"), u.DependOn.Name+":"+string(u.DependOn.Phase), u.String()))
+	}
+	return allErrs
+}
+
+// validateItems 校验withItems/withParam，withParam 引用parameters 时其值必须是JSON 数组
+func validateItems(ws v1alpha1.WorkflowStep, parameters map[string]string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(ws.WithItems) == 0 && len(ws.WithParam) == 0 {
+		if ws.Parallelism > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("parallelism"), "parallelism is only allowed with withItems or withParam"))
+		}
+		return allErrs
+	}
+	if len(ws.WithItems) > 0 && len(ws.WithParam) > 0 {
+		return append(allErrs, field.Forbidden(fldPath.Child("withParam"), "withItems and withParam are mutually exclusive"))
+	}
+	withParamPath := fldPath.Child("withParam")
+	switch {
+	case len(ws.WithParam) == 0:
+	case strings.HasPrefix(ws.WithParam, "parameters."):
+		value, ok := parameters[strings.TrimPrefix(ws.WithParam, "parameters.")]
+		if !ok {
+			allErrs = append(allErrs, field.NotFound(withParamPath, ws.WithParam))
+			break
+		}
+		items := make([]json.RawMessage, 0)
+		if err := json.Unmarshal([]byte(value), &items); err != nil {
+			allErrs = append(allErrs, field.Invalid(withParamPath, ws.WithParam, fmt.Sprintf("value is not a JSON array: %v", err)))
+		}
+	case !strings.HasPrefix(ws.WithParam, "attributes."):
+		allErrs = append(allErrs, field.Invalid(withParamPath, ws.WithParam, "withParam must reference parameters.xx or attributes.xx"))
+	}
+	return allErrs
+}
+
+// validateOutputs 校验outputs、exportAttributes，声明了outputs 时只能导出声明过的key
+func validateOutputs(ws v1alpha1.WorkflowStep, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	outputs := map[string]bool{}
+	for i, k := range ws.Outputs {
+		outputPath := fldPath.Child("outputs").Index(i)
+		if k == "" {
+			allErrs = append(allErrs, field.Required(outputPath, ""))
+			continue
+		}
+		if outputs[k] {
+			allErrs = append(allErrs, field.Duplicate(outputPath, k))
+		}
+		outputs[k] = true
+	}
+	if len(ws.ExportAttributes) == 0 {
+		return allErrs
+	}
+	exportPath := fldPath.Child("exportAttributes")
+	if len(ws.WithItems) > 0 || len(ws.WithParam) > 0 {
+		return append(allErrs, field.Forbidden(exportPath, "exportAttributes is not allowed with withItems or withParam"))
+	}
+	for k := range ws.ExportAttributes {
+		v := ws.ExportAttributes[k]
+		switch {
+		case k == "":
+			allErrs = append(allErrs, field.Required(exportPath, "attribute key must not be empty"))
+		case v == "":
+			allErrs = append(allErrs, field.Required(exportPath.Key(k), ""))
+		case len(ws.Outputs) > 0 && !outputs[v]:
+			allErrs = append(allErrs, field.Invalid(exportPath.Key(k), v, "output is not declared in outputs"))
+		}
+	}
+	return allErrs
+}
+
+// validateParameterTemplates 校验step parameters 中 {{...}} 模板引用的变量：workflow 的parameters 必须存在，step 汇总的attributes 不检查，
+// steps.<step>.attributes、outputs 只能引用上游step 的，item 只能在withItems/withParam 展开的step 中使用
+func validateParameterTemplates(ws v1alpha1.WorkflowStep, spec *v1alpha1.WorkflowSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	workflowSteps := map[string]v1alpha1.WorkflowStep{}
+	for _, s := range spec.Steps {
+		workflowSteps[s.Name] = s
+	}
+	var upstream map[string]bool
+	for k, v := range ws.StepTemplate.Parameters {
+		parameterPath := fldPath.Key(k)
+		for _, variable := range expression.TemplateVariables(v) {
+			parts := strings.SplitN(variable, ".", 4)
+			switch {
+			case variable == "item":
+				if len(ws.WithItems) == 0 && len(ws.WithParam) == 0 {
+					allErrs = append(allErrs, field.Invalid(parameterPath, v, "item is only allowed with withItems or withParam"))
+				}
+			case strings.HasPrefix(variable, "parameters.") || strings.HasPrefix(variable, "workflow.parameters."):
+				if _, ok := spec.Parameters[strings.TrimPrefix(strings.TrimPrefix(variable, "workflow."), "parameters.")]; !ok {
+					allErrs = append(allErrs, field.NotFound(parameterPath, variable))
+				}
+			case strings.HasPrefix(variable, "attributes."):
+				// step 汇总的attributes 运行时才知道
+			case len(parts) == 4 && parts[0] == "steps" && (parts[2] == "attributes" || parts[2] == "outputs"):
+				if upstream == nil {
+					upstream = dag.Upstream(spec.Steps, ws.Name)
+				}
+				dependOn, ok := workflowSteps[parts[1]]
+				switch {
+				case !ok:
+					allErrs = append(allErrs, field.NotFound(parameterPath, variable))
+				case !upstream[parts[1]]:
+					allErrs = append(allErrs, field.Invalid(parameterPath, v, fmt.Sprintf("step %s is not an upstream step", parts[1])))
+				case len(dependOn.WithItems) > 0 || len(dependOn.WithParam) > 0:
+					allErrs = append(allErrs, field.Invalid(parameterPath, v, fmt.Sprintf("step %s is expanded by withItems or withParam", parts[1])))
+				}
+			default:
+				allErrs = append(allErrs, field.Invalid(parameterPath, v,
+					fmt.Sprintf("unknown variable %s, must be parameters.xx, workflow.parameters.xx, attributes.xx, steps.<step>.attributes.xx, steps.<step>.outputs.<path> or item", variable)))
+			}
+		}
+	}
+	return allErrs
+}
+
+func validateStepSpec(spec *v1alpha1.StepSpec, cfg *controller2.Config, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	typePath := fldPath.Child("type")
+	if spec.Type == "" {
+		return append(allErrs, field.Required(typePath, ""))
+	}
+	if !isRegisteredStepType(spec.Type) {
+		allErrs = append(allErrs, field.Invalid(typePath, spec.Type, "step type is not registered"))
+	}
+	if !isConfiguredStepType(spec.Type, cfg) {
+		allErrs = append(allErrs, field.Invalid(typePath, spec.Type, "no step controller is configured for the step type"))
+	}
+	allErrs = append(allErrs, validateParametersFrom(spec, fldPath.Child("parametersFrom"))...)
+	return allErrs
+}
+
+// validateParametersFrom 每个parameter 只能有一个来源，key 不能与parameters 重复
+func validateParametersFrom(spec *v1alpha1.StepSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	names := map[string]bool{}
+	for i, from := range spec.ParametersFrom {
+		fromPath := fldPath.Index(i)
+		namePath := fromPath.Child("name")
+		switch _, ok := spec.Parameters[from.Name]; {
+		case from.Name == "":
+			allErrs = append(allErrs, field.Required(namePath, ""))
+		case ok || names[from.Name]:
+			allErrs = append(allErrs, field.Duplicate(namePath, from.Name))
+		}
+		names[from.Name] = true
+		switch {
+		case from.SecretKeyRef != nil && from.ConfigMapKeyRef != nil:
+			allErrs = append(allErrs, field.Forbidden(fromPath, "secretKeyRef and configMapKeyRef are mutually exclusive"))
+		case from.SecretKeyRef != nil:
+			if from.SecretKeyRef.Name == "" {
+				allErrs = append(allErrs, field.Required(fromPath.Child("secretKeyRef", "name"), ""))
+			}
+			if from.SecretKeyRef.Key == "" {
+				allErrs = append(allErrs, field.Required(fromPath.Child("secretKeyRef", "key"), ""))
+			}
+		case from.ConfigMapKeyRef != nil:
+			if from.ConfigMapKeyRef.Name == "" {
+				allErrs = append(allErrs, field.Required(fromPath.Child("configMapKeyRef", "name"), ""))
+			}
+			if from.ConfigMapKeyRef.Key == "" {
+				allErrs = append(allErrs, field.Required(fromPath.Child("configMapKeyRef", "key"), ""))
+			}
+		default:
+			allErrs = append(allErrs, field.Required(fromPath, "one of secretKeyRef and configMapKeyRef is required"))
+		}
+	}
+	return allErrs
+}
+
+func isStepPhase(phase v1alpha1.StepPhase) bool {
+	for _, p := range stepPhases {
+		if string(phase) == p {
+			return true
+		}
+	}
+	return false
+}
+
+func isRegisteredStepType(stepType string) bool {
+	if _, ok := stepinterface.FactoryV2[stepType]; ok {
+		return true
+	}
+	_, ok := stepinterface.Factory[stepType]
+	return ok
+}
+
+// isConfiguredStepType 每个step 类型由ControllerConfig.Steps 中对应的step controller 驱动
+func isConfiguredStepType(stepType string, cfg *controller2.Config) bool {
+	for _, stepConfig := range cfg.Steps {
+		if stepConfig.Kind == stepType {
+			return true
+		}
+	}
+	return false
+}
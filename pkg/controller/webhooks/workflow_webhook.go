@@ -0,0 +1,119 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/controller/manager"
+	controller2 "github.com/qiankunli/workflow/pkg/options/controller"
+)
+
+type workflowWebhook struct {
+	config *controller2.Config
+	log    logr.Logger
+}
+
+// RegisterWorkflowWebhook ...
+func RegisterWorkflowWebhook(mgr ctrl.Manager, controllerCtx *manager.ControllerContext) error {
+	const name = "workflow-webhook"
+
+	w := &workflowWebhook{
+		config: controllerCtx.Config.ControllerConfig,
+		log:    ctrl.LoggerFrom(context.Background()).WithName(name),
+	}
+	err := ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.Workflow{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+	if err != nil {
+		return fmt.Errorf("failed to set up with manager: %w", err)
+	}
+	w.log.Info("succeeded to set up with manager")
+	return nil
+}
+
+// Default ...
+func (w *workflowWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	workflow, ok := obj.(*v1alpha1.Workflow)
+	if !ok {
+		return fmt.Errorf("expected a Workflow but got a %T", obj)
+	}
+	defaultWorkflowSpec(&workflow.Spec)
+	return nil
+}
+
+// ValidateCreate ...
+func (w *workflowWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	workflow, ok := obj.(*v1alpha1.Workflow)
+	if !ok {
+		return fmt.Errorf("expected a Workflow but got a %T", obj)
+	}
+	allErrs := validateWorkflowSpec(workflow.Name, &workflow.Spec, w.config, field.NewPath("spec"))
+	return toInvalidError("Workflow", workflow.Name, allErrs)
+}
+
+// ValidateUpdate ...
+func (w *workflowWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	oldWorkflow, ok := oldObj.(*v1alpha1.Workflow)
+	if !ok {
+		return fmt.Errorf("expected a Workflow but got a %T", oldObj)
+	}
+	workflow, ok := newObj.(*v1alpha1.Workflow)
+	if !ok {
+		return fmt.Errorf("expected a Workflow but got a %T", newObj)
+	}
+	// 只更新metadata（比如删除时去掉finalizer）的请求不做校验
+	if !workflow.DeletionTimestamp.IsZero() || apiequality.Semantic.DeepEqual(oldWorkflow.Spec, workflow.Spec) {
+		return nil
+	}
+	allErrs := validateWorkflowSpec(workflow.Name, &workflow.Spec, w.config, field.NewPath("spec"))
+	allErrs = append(allErrs, validateWorkflowSpecUpdate(oldWorkflow, workflow, field.NewPath("spec"))...)
+	return toInvalidError("Workflow", workflow.Name, allErrs)
+}
+
+// ValidateDelete ...
+func (w *workflowWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+// validateWorkflowSpecUpdate workflow 开始运行后，已经创建的step 不会再变化，steps、parameters、queue、workflowTemplateRef 不能修改，
+// desiredState 设置为RollBacked 后不能撤销
+func validateWorkflowSpecUpdate(oldWorkflow, workflow *v1alpha1.Workflow, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if oldWorkflow.Status.Phase == "" || oldWorkflow.Status.Phase == v1alpha1.WorkflowPending {
+		return allErrs
+	}
+	const msg = "field is immutable after workflow started"
+	if oldWorkflow.Spec.Queue != workflow.Spec.Queue {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("queue"), msg))
+	}
+	if !apiequality.Semantic.DeepEqual(oldWorkflow.Spec.Parameters, workflow.Spec.Parameters) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("parameters"), msg))
+	}
+	if !apiequality.Semantic.DeepEqual(oldWorkflow.Spec.Steps, workflow.Spec.Steps) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("steps"), msg))
+	}
+	if !apiequality.Semantic.DeepEqual(oldWorkflow.Spec.WorkflowTemplateRef, workflow.Spec.WorkflowTemplateRef) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("workflowTemplateRef"), msg))
+	}
+	if oldWorkflow.Spec.DesiredState == v1alpha1.DesiredRollBacked && workflow.Spec.DesiredState != v1alpha1.DesiredRollBacked {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("desiredState"), "rollback can not be cancelled"))
+	}
+	return allErrs
+}
+
+func toInvalidError(kind, name string, allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return k8sapierrors.NewInvalid(v1alpha1.Kind(kind), name, allErrs)
+}
@@ -0,0 +1,418 @@
+package webhooks
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	_ "github.com/qiankunli/workflow/pkg/controller/step/common"
+	controller2 "github.com/qiankunli/workflow/pkg/options/controller"
+)
+
+func newTestWorkflowWebhook() *workflowWebhook {
+	return &workflowWebhook{
+		config: &controller2.Config{
+			Steps: []controller2.StepConfig{{Kind: "empty"}},
+		},
+		log: logr.Discard(),
+	}
+}
+
+func newTestWorkflow(steps ...v1alpha1.WorkflowStep) *v1alpha1.Workflow {
+	workflow := &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{Queue: "default", Steps: steps}}
+	workflow.Name = "example"
+	return workflow
+}
+
+func newTestStep(name string, dependOns ...string) v1alpha1.WorkflowStep {
+	ws := v1alpha1.WorkflowStep{Name: name, StepTemplate: v1alpha1.StepSpec{Type: "empty"}}
+	for _, dependOn := range dependOns {
+		ws.DependOns = append(ws.DependOns, v1alpha1.DependOn{Name: dependOn, Phase: v1alpha1.StepSuccess})
+	}
+	return ws
+}
+
+func TestValidateCreate(t *testing.T) {
+	cases := []struct {
+		name     string
+		workflow *v1alpha1.Workflow
+		// 为空表示校验通过
+		expectErr string
+	}{
+		{
+			name:     "valid",
+			workflow: newTestWorkflow(newTestStep("a"), newTestStep("b", "a"), newTestStep("c", "a", "b")),
+		},
+		{
+			name:      "duplicate step name",
+			workflow:  newTestWorkflow(newTestStep("a"), newTestStep("a")),
+			expectErr: "Duplicate value",
+		},
+		{
+			name:      "empty step name",
+			workflow:  newTestWorkflow(newTestStep("")),
+			expectErr: "spec.steps[0].name: Required value",
+		},
+		{
+			name:      "invalid step name",
+			workflow:  newTestWorkflow(newTestStep("A_B")),
+			expectErr: "spec.steps[0].name: Invalid value",
+		},
+		{
+			name:      "depend on nonexistent step",
+			workflow:  newTestWorkflow(newTestStep("a", "b")),
+			expectErr: "spec.steps[0].dependOns[0].name: Not found",
+		},
+		{
+			name:      "depend on itself",
+			workflow:  newTestWorkflow(newTestStep("a", "a")),
+			expectErr: "step can not depend on itself",
+		},
+		{
+			name:      "dependency cycle",
+			workflow:  newTestWorkflow(newTestStep("a", "c"), newTestStep("b", "a"), newTestStep("c", "b")),
+			expectErr: "a -> c -> b -> a",
+		},
+		{
+			name: "invalid depend on phase",
+			workflow: newTestWorkflow(newTestStep("a"), v1alpha1.WorkflowStep{
+				Name:         "b",
+				DependOns:    []v1alpha1.DependOn{{Name: "a"}},
+				StepTemplate: v1alpha1.StepSpec{Type: "empty"},
+			}),
+			expectErr: "spec.steps[1].dependOns[0].phase: Unsupported value",
+		},
+		{
+			name: "unregistered step type",
+			workflow: newTestWorkflow(v1alpha1.WorkflowStep{
+				Name:         "a",
+				StepTemplate: v1alpha1.StepSpec{Type: "unknown"},
+			}),
+			expectErr: "step type is not registered",
+		},
+		{
+			name: "step type without step controller",
+			workflow: newTestWorkflow(v1alpha1.WorkflowStep{
+				Name:         "a",
+				StepTemplate: v1alpha1.StepSpec{Type: "unknown"},
+			}),
+			expectErr: "no step controller is configured for the step type",
+		},
+		{
+			name: "invalid when",
+			workflow: newTestWorkflow(v1alpha1.WorkflowStep{
+				Name:         "a",
+				When:         "[parameters.env] ==",
+				StepTemplate: v1alpha1.StepSpec{Type: "empty"},
+			}),
+			expectErr: "spec.steps[0].when: Invalid value",
+		},
+		{
+			name: "any of failed dependOns",
+			workflow: newTestWorkflow(newTestStep("a"), newTestStep("b"), v1alpha1.WorkflowStep{
+				Name:         "c",
+				DependOns:    []v1alpha1.DependOn{{Name: "a", Phase: v1alpha1.StepFailed}, {Name: "b", Phase: v1alpha1.StepSuccess}},
+				DependMode:   v1alpha1.DependAny,
+				StepTemplate: v1alpha1.StepSpec{Type: "empty"},
+			}),
+		},
+		{
+			name: "depend on failed phase",
+			workflow: newTestWorkflow(newTestStep("a"), v1alpha1.WorkflowStep{
+				Name:         "b",
+				DependOns:    []v1alpha1.DependOn{{Name: "a", Phase: v1alpha1.StepRollBacked}},
+				StepTemplate: v1alpha1.StepSpec{Type: "empty"},
+			}),
+		},
+		{
+			name: "depend on rolling back phase",
+			workflow: newTestWorkflow(newTestStep("a"), v1alpha1.WorkflowStep{
+				Name:         "b",
+				DependOns:    []v1alpha1.DependOn{{Name: "a", Phase: v1alpha1.StepRollingBack}},
+				StepTemplate: v1alpha1.StepSpec{Type: "empty"},
+			}),
+			expectErr: "RollingBack is a transient phase",
+		},
+		{
+			name: "dependExpression references a step not in dependOns",
+			workflow: newTestWorkflow(newTestStep("a"), newTestStep("b"), v1alpha1.WorkflowStep{
+				Name:             "c",
+				DependOns:        []v1alpha1.DependOn{{Name: "a", Phase: v1alpha1.StepSuccess}},
+				DependMode:       v1alpha1.DependExpression,
+				DependExpression: "a || b",
+				StepTemplate:     v1alpha1.StepSpec{Type: "empty"},
+			}),
+			expectErr: "spec.steps[2].dependExpression: Invalid value",
+		},
+		{
+			name: "dependExpression without Expression dependMode",
+			workflow: newTestWorkflow(newTestStep("a"), v1alpha1.WorkflowStep{
+				Name:             "b",
+				DependOns:        []v1alpha1.DependOn{{Name: "a", Phase: v1alpha1.StepSuccess}},
+				DependExpression: "a",
+				StepTemplate:     v1alpha1.StepSpec{Type: "empty"},
+			}),
+			expectErr: "spec.steps[1].dependExpression: Forbidden",
+		},
+		{
+			name: "withParam references an unknown source",
+			workflow: newTestWorkflow(v1alpha1.WorkflowStep{
+				Name:         "a",
+				WithParam:    "regions",
+				StepTemplate: v1alpha1.StepSpec{Type: "empty"},
+			}),
+			expectErr: "spec.steps[0].withParam: Invalid value",
+		},
+		{
+			name: "withItems and withParam",
+			workflow: newTestWorkflow(v1alpha1.WorkflowStep{
+				Name:         "a",
+				WithItems:    []string{"a"},
+				WithParam:    "attributes.regions",
+				StepTemplate: v1alpha1.StepSpec{Type: "empty"},
+			}),
+			expectErr: "withItems and withParam are mutually exclusive",
+		},
+		{
+			name: "parallelism without withItems",
+			workflow: newTestWorkflow(v1alpha1.WorkflowStep{
+				Name:         "a",
+				Parallelism:  2,
+				StepTemplate: v1alpha1.StepSpec{Type: "empty"},
+			}),
+			expectErr: "spec.steps[0].parallelism: Forbidden",
+		},
+		{
+			name: "parameter templates",
+			workflow: func() *v1alpha1.Workflow {
+				b := newTestStep("b", "a")
+				b.StepTemplate.Parameters = map[string]string{"vpc": "{{steps.a.attributes.vpcId}}", "region": "{{ workflow.parameters.region }}"}
+				workflow := newTestWorkflow(newTestStep("a"), b)
+				workflow.Spec.Parameters = map[string]string{"region": "us-east"}
+				return workflow
+			}(),
+		},
+		{
+			name: "parameter template references a step not upstream",
+			workflow: func() *v1alpha1.Workflow {
+				b := newTestStep("b")
+				b.StepTemplate.Parameters = map[string]string{"vpc": "{{steps.a.attributes.vpcId}}"}
+				return newTestWorkflow(newTestStep("a"), b)
+			}(),
+			expectErr: "step a is not an upstream step",
+		},
+		{
+			name: "parameter template references outputs of an upstream step",
+			workflow: func() *v1alpha1.Workflow {
+				b := newTestStep("b", "a")
+				b.StepTemplate.Parameters = map[string]string{"subnet": "{{steps.a.outputs.subnets.0.id}}"}
+				return newTestWorkflow(newTestStep("a"), b)
+			}(),
+		},
+		{
+			name: "parameter template references outputs of a step not upstream",
+			workflow: func() *v1alpha1.Workflow {
+				b := newTestStep("b")
+				b.StepTemplate.Parameters = map[string]string{"subnet": "{{steps.a.outputs.subnets.0.id}}"}
+				return newTestWorkflow(newTestStep("a"), b)
+			}(),
+			expectErr: "step a is not an upstream step",
+		},
+		{
+			name: "parameter template references an unknown parameter",
+			workflow: func() *v1alpha1.Workflow {
+				a := newTestStep("a")
+				a.StepTemplate.Parameters = map[string]string{"region": "{{workflow.parameters.region}}"}
+				return newTestWorkflow(a)
+			}(),
+			expectErr: "spec.steps[0].stepTemplate.parameters[region]: Not found",
+		},
+		{
+			name: "parameter template references an unknown variable",
+			workflow: func() *v1alpha1.Workflow {
+				a := newTestStep("a")
+				a.StepTemplate.Parameters = map[string]string{"region": "{{params.region}}"}
+				return newTestWorkflow(a)
+			}(),
+			expectErr: "unknown variable params.region",
+		},
+		{
+			name: "parameter template references a parameter without workflow prefix",
+			workflow: func() *v1alpha1.Workflow {
+				a := newTestStep("a")
+				a.StepTemplate.Parameters = map[string]string{"region": "{{parameters.region}}", "vpc": "{{attributes.vpcId}}"}
+				return newTestWorkflow(a)
+			}(),
+			expectErr: "spec.steps[0].stepTemplate.parameters[region]: Not found",
+		},
+		{
+			name: "outputs and exportAttributes",
+			workflow: func() *v1alpha1.Workflow {
+				a := newTestStep("a")
+				a.Outputs = []string{"vpcId", "subnetId"}
+				a.ExportAttributes = map[string]string{"vpc": "vpcId"}
+				return newTestWorkflow(a)
+			}(),
+		},
+		{
+			name: "duplicate output",
+			workflow: func() *v1alpha1.Workflow {
+				a := newTestStep("a")
+				a.Outputs = []string{"vpcId", "vpcId"}
+				return newTestWorkflow(a)
+			}(),
+			expectErr: "spec.steps[0].outputs[1]: Duplicate value",
+		},
+		{
+			name: "export undeclared output",
+			workflow: func() *v1alpha1.Workflow {
+				a := newTestStep("a")
+				a.Outputs = []string{"vpcId"}
+				a.ExportAttributes = map[string]string{"subnet": "subnetId"}
+				return newTestWorkflow(a)
+			}(),
+			expectErr: "output is not declared in outputs",
+		},
+		{
+			name: "export the same attribute from two steps",
+			workflow: func() *v1alpha1.Workflow {
+				a, b := newTestStep("a"), newTestStep("b")
+				a.ExportAttributes = map[string]string{"vpc": "vpcId"}
+				b.ExportAttributes = map[string]string{"vpc": "id"}
+				return newTestWorkflow(a, b)
+			}(),
+			expectErr: "spec.steps[1].exportAttributes: Duplicate value",
+		},
+		{
+			name: "exportAttributes with withItems",
+			workflow: func() *v1alpha1.Workflow {
+				a := newTestStep("a")
+				a.WithItems = []string{"x"}
+				a.ExportAttributes = map[string]string{"vpc": "vpcId"}
+				return newTestWorkflow(a)
+			}(),
+			expectErr: "exportAttributes is not allowed with withItems or withParam",
+		},
+		{
+			name: "parametersFrom",
+			workflow: func() *v1alpha1.Workflow {
+				a := newTestStep("a")
+				a.StepTemplate.ParametersFrom = []v1alpha1.ParameterFrom{
+					{Name: "password", SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"}},
+					{Name: "endpoint", ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "endpoint"}},
+				}
+				return newTestWorkflow(a)
+			}(),
+		},
+		{
+			name: "parametersFrom without source",
+			workflow: func() *v1alpha1.Workflow {
+				a := newTestStep("a")
+				a.StepTemplate.ParametersFrom = []v1alpha1.ParameterFrom{{Name: "password"}}
+				return newTestWorkflow(a)
+			}(),
+			expectErr: "one of secretKeyRef and configMapKeyRef is required",
+		},
+		{
+			name: "parametersFrom duplicates parameters",
+			workflow: func() *v1alpha1.Workflow {
+				a := newTestStep("a")
+				a.StepTemplate.Parameters = map[string]string{"password": "x"}
+				a.StepTemplate.ParametersFrom = []v1alpha1.ParameterFrom{
+					{Name: "password", SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"}},
+				}
+				return newTestWorkflow(a)
+			}(),
+			expectErr: "spec.steps[0].stepTemplate.parametersFrom[0].name: Duplicate value",
+		},
+		{
+			name: "workflowTemplateRef",
+			workflow: &v1alpha1.Workflow{Spec: v1alpha1.WorkflowSpec{
+				Queue:               "default",
+				Parameters:          map[string]string{"env": "prod"},
+				WorkflowTemplateRef: &v1alpha1.WorkflowTemplateRef{Name: "deploy"},
+			}},
+		},
+		{
+			name: "workflowTemplateRef with steps",
+			workflow: func() *v1alpha1.Workflow {
+				workflow := newTestWorkflow(newTestStep("a"))
+				workflow.Spec.WorkflowTemplateRef = &v1alpha1.WorkflowTemplateRef{Name: "deploy"}
+				return workflow
+			}(),
+			expectErr: "steps and workflowTemplateRef are mutually exclusive",
+		},
+	}
+	w := newTestWorkflowWebhook()
+	for _, c := range cases {
+		err := w.ValidateCreate(context.Background(), c.workflow)
+		if c.expectErr == "" {
+			if err != nil {
+				t.Errorf("%s: expect no error, got %v", c.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.expectErr) {
+			t.Errorf("%s: expect error contains %q, got %v", c.name, c.expectErr, err)
+		}
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	w := newTestWorkflowWebhook()
+	oldWorkflow := newTestWorkflow(newTestStep("a"))
+	workflow := newTestWorkflow(newTestStep("a"), newTestStep("b", "a"))
+	if err := w.ValidateUpdate(context.Background(), oldWorkflow, workflow); err != nil {
+		t.Errorf("pending workflow: expect no error, got %v", err)
+	}
+
+	oldWorkflow.Status.Phase = v1alpha1.WorkflowRunning
+	if err := w.ValidateUpdate(context.Background(), oldWorkflow, workflow); err == nil ||
+		!strings.Contains(err.Error(), "spec.steps: Forbidden") {
+		t.Errorf("running workflow: expect steps forbidden, got %v", err)
+	}
+
+	workflow = oldWorkflow.DeepCopy()
+	workflow.Spec.Priority = 10
+	workflow.Spec.ActiveDeadlineSeconds = 60
+	workflow.Spec.Suspend = true
+	workflow.Spec.DesiredState = v1alpha1.DesiredRollBacked
+	if err := w.ValidateUpdate(context.Background(), oldWorkflow, workflow); err != nil {
+		t.Errorf("running workflow: expect no error, got %v", err)
+	}
+
+	oldWorkflow = workflow.DeepCopy()
+	workflow.Spec.DesiredState = ""
+	if err := w.ValidateUpdate(context.Background(), oldWorkflow, workflow); err == nil ||
+		!strings.Contains(err.Error(), "spec.desiredState: Forbidden") {
+		t.Errorf("running workflow: expect desiredState forbidden, got %v", err)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	w := newTestWorkflowWebhook()
+	workflow := newTestWorkflow(newTestStep("a"), v1alpha1.WorkflowStep{
+		Name:      "b",
+		DependOns: []v1alpha1.DependOn{{Name: "a"}},
+	})
+	workflow.Spec.Queue = ""
+	if err := w.Default(context.Background(), workflow); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if workflow.Spec.Queue != defaultQueue {
+		t.Errorf("expect queue %q, got %q", defaultQueue, workflow.Spec.Queue)
+	}
+	if workflow.Spec.RollbackPolicy != v1alpha1.PreserveOnFailure {
+		t.Errorf("expect rollbackPolicy %q, got %q", v1alpha1.PreserveOnFailure, workflow.Spec.RollbackPolicy)
+	}
+	if phase := workflow.Spec.Steps[1].DependOns[0].Phase; phase != v1alpha1.StepSuccess {
+		t.Errorf("expect dependOn phase %q, got %q", v1alpha1.StepSuccess, phase)
+	}
+	if period := workflow.Spec.Steps[1].StepTemplate.PollPeriodSeconds; period != 10 {
+		t.Errorf("expect pollPeriodSeconds 10, got %d", period)
+	}
+}
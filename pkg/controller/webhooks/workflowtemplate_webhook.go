@@ -0,0 +1,139 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/controller/manager"
+	controller2 "github.com/qiankunli/workflow/pkg/options/controller"
+)
+
+// workflowTemplateWebhook 同时处理WorkflowTemplate 和ClusterWorkflowTemplate
+type workflowTemplateWebhook struct {
+	config *controller2.Config
+	log    logr.Logger
+}
+
+// RegisterWorkflowTemplateWebhook ...
+func RegisterWorkflowTemplateWebhook(mgr ctrl.Manager, controllerCtx *manager.ControllerContext) error {
+	const name = "workflowtemplate-webhook"
+
+	w := &workflowTemplateWebhook{
+		config: controllerCtx.Config.ControllerConfig,
+		log:    ctrl.LoggerFrom(context.Background()).WithName(name),
+	}
+	for _, obj := range []runtime.Object{&v1alpha1.WorkflowTemplate{}, &v1alpha1.ClusterWorkflowTemplate{}} {
+		err := ctrl.NewWebhookManagedBy(mgr).
+			For(obj).
+			WithDefaulter(w).
+			WithValidator(w).
+			Complete()
+		if err != nil {
+			return fmt.Errorf("failed to set up with manager: %w", err)
+		}
+	}
+	w.log.Info("succeeded to set up with manager")
+	return nil
+}
+
+// templateOf 返回模板的kind、name 和spec
+func templateOf(obj runtime.Object) (string, string, *v1alpha1.WorkflowTemplateSpec, error) {
+	switch template := obj.(type) {
+	case *v1alpha1.WorkflowTemplate:
+		return "WorkflowTemplate", template.Name, &template.Spec, nil
+	case *v1alpha1.ClusterWorkflowTemplate:
+		return "ClusterWorkflowTemplate", template.Name, &template.Spec, nil
+	}
+	return "", "", nil, fmt.Errorf("expected a WorkflowTemplate or ClusterWorkflowTemplate but got a %T", obj)
+}
+
+// Default ...
+func (w *workflowTemplateWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	_, _, spec, err := templateOf(obj)
+	if err != nil {
+		return err
+	}
+	defaultWorkflowSpec(&spec.Template)
+	return nil
+}
+
+// ValidateCreate ...
+func (w *workflowTemplateWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	kind, name, spec, err := templateOf(obj)
+	if err != nil {
+		return err
+	}
+	return toInvalidError(kind, name, validateWorkflowTemplateSpec(name, spec, w.config, field.NewPath("spec")))
+}
+
+// ValidateUpdate 模板的修改不影响已经开始的workflow，可以任意修改
+func (w *workflowTemplateWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	return w.ValidateCreate(ctx, newObj)
+}
+
+// ValidateDelete ...
+func (w *workflowTemplateWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+func validateWorkflowTemplateSpec(name string, spec *v1alpha1.WorkflowTemplateSpec, cfg *controller2.Config, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	templatePath := fldPath.Child("template")
+	if spec.Template.WorkflowTemplateRef != nil {
+		allErrs = append(allErrs, field.Forbidden(templatePath.Child("workflowTemplateRef"), "template can not reference another template"))
+	}
+	// 输入参数的默认值覆盖模板的parameters，没有默认值的参数由workflow 设置，这里只校验其存在
+	template := spec.Template.DeepCopy()
+	if template.Parameters == nil {
+		template.Parameters = map[string]string{}
+	}
+	inputNames := map[string]bool{}
+	for i, input := range spec.Inputs {
+		namePath := fldPath.Child("inputs").Index(i).Child("name")
+		if input.Name == "" {
+			allErrs = append(allErrs, field.Required(namePath, ""))
+			continue
+		}
+		if inputNames[input.Name] {
+			allErrs = append(allErrs, field.Duplicate(namePath, input.Name))
+			continue
+		}
+		inputNames[input.Name] = true
+		if input.Default != nil {
+			template.Parameters[input.Name] = *input.Default
+		} else if _, ok := template.Parameters[input.Name]; !ok {
+			template.Parameters[input.Name] = "[]"
+		}
+	}
+	template.WorkflowTemplateRef = nil
+	allErrs = append(allErrs, validateTemplateOnlyFields(&spec.Template, templatePath)...)
+	return append(allErrs, validateWorkflowSpec(name, template, cfg, templatePath)...)
+}
+
+// validateTemplateOnlyFields queue、priority、rollbackPolicy、suspend、desiredState 不会从模板合并到workflow，模板中只能为默认值
+func validateTemplateOnlyFields(template *v1alpha1.WorkflowSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	const msg = "not supported in template, set it on the workflow"
+	if template.Queue != "" && template.Queue != defaultQueue {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("queue"), msg))
+	}
+	if template.Priority != 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("priority"), msg))
+	}
+	if template.RollbackPolicy != "" && template.RollbackPolicy != v1alpha1.PreserveOnFailure {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("rollbackPolicy"), msg))
+	}
+	if template.Suspend {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("suspend"), msg))
+	}
+	if template.DesiredState != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("desiredState"), msg))
+	}
+	return allErrs
+}
@@ -0,0 +1,107 @@
+package webhooks
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	controller2 "github.com/qiankunli/workflow/pkg/options/controller"
+)
+
+func TestValidateWorkflowTemplate(t *testing.T) {
+	w := &workflowTemplateWebhook{
+		config: &controller2.Config{
+			Steps: []controller2.StepConfig{{Kind: "empty"}},
+		},
+		log: logr.Discard(),
+	}
+	regions := `["us","eu"]`
+	cases := []struct {
+		name     string
+		template *v1alpha1.WorkflowTemplateSpec
+		// 为空表示校验通过
+		expectErr string
+	}{
+		{
+			name: "withParam references an undeclared parameter",
+			template: &v1alpha1.WorkflowTemplateSpec{
+				Inputs: []v1alpha1.TemplateParameter{{Name: "env"}, {Name: "regions", Default: &regions}},
+				Template: v1alpha1.WorkflowSpec{Queue: "default", Steps: []v1alpha1.WorkflowStep{
+					newTestStep("a"),
+					{Name: "b", WithParam: "parameters.regions", StepTemplate: v1alpha1.StepSpec{Type: "empty"}},
+					{Name: "c", WithParam: "parameters.shards", StepTemplate: v1alpha1.StepSpec{Type: "empty"}},
+				}},
+			},
+			expectErr: "spec.template.steps[2].withParam: Not found",
+		},
+		{
+			name: "required input referenced by withParam",
+			template: &v1alpha1.WorkflowTemplateSpec{
+				Inputs: []v1alpha1.TemplateParameter{{Name: "shards"}},
+				Template: v1alpha1.WorkflowSpec{Queue: "default", Steps: []v1alpha1.WorkflowStep{
+					{Name: "a", WithParam: "parameters.shards", StepTemplate: v1alpha1.StepSpec{Type: "empty"}},
+				}},
+			},
+		},
+		{
+			name: "duplicate input",
+			template: &v1alpha1.WorkflowTemplateSpec{
+				Inputs:   []v1alpha1.TemplateParameter{{Name: "env"}, {Name: "env"}},
+				Template: v1alpha1.WorkflowSpec{Queue: "default", Steps: []v1alpha1.WorkflowStep{newTestStep("a")}},
+			},
+			expectErr: "spec.inputs[1].name: Duplicate value",
+		},
+		{
+			name: "reference another template",
+			template: &v1alpha1.WorkflowTemplateSpec{
+				Template: v1alpha1.WorkflowSpec{Queue: "default", WorkflowTemplateRef: &v1alpha1.WorkflowTemplateRef{Name: "other"}},
+			},
+			expectErr: "template can not reference another template",
+		},
+		{
+			name: "template with queue",
+			template: &v1alpha1.WorkflowTemplateSpec{
+				Template: v1alpha1.WorkflowSpec{Queue: "high", Steps: []v1alpha1.WorkflowStep{newTestStep("a")}},
+			},
+			expectErr: "spec.template.queue: Forbidden",
+		},
+		{
+			name: "template with rollbackPolicy",
+			template: &v1alpha1.WorkflowTemplateSpec{
+				Template: v1alpha1.WorkflowSpec{Queue: "default", RollbackPolicy: v1alpha1.Always, Steps: []v1alpha1.WorkflowStep{newTestStep("a")}},
+			},
+			expectErr: "spec.template.rollbackPolicy: Forbidden",
+		},
+		{
+			name: "template with suspend",
+			template: &v1alpha1.WorkflowTemplateSpec{
+				Template: v1alpha1.WorkflowSpec{Queue: "default", Suspend: true, Steps: []v1alpha1.WorkflowStep{newTestStep("a")}},
+			},
+			expectErr: "spec.template.suspend: Forbidden",
+		},
+		{
+			name: "template with desiredState",
+			template: &v1alpha1.WorkflowTemplateSpec{
+				Template: v1alpha1.WorkflowSpec{Queue: "default", DesiredState: v1alpha1.DesiredRollBacked, Steps: []v1alpha1.WorkflowStep{newTestStep("a")}},
+			},
+			expectErr: "spec.template.desiredState: Forbidden",
+		},
+	}
+	for _, c := range cases {
+		template := &v1alpha1.ClusterWorkflowTemplate{Spec: *c.template}
+		template.Name = "deploy"
+		err := w.ValidateCreate(context.Background(), template)
+		if c.expectErr == "" {
+			if err != nil {
+				t.Errorf("%s: expect no error, got %v", c.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.expectErr) {
+			t.Errorf("%s: expect error contains %q, got %v", c.name, c.expectErr, err)
+		}
+	}
+}
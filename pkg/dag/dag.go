@@ -0,0 +1,337 @@
+// Package dag analyzes the dependOns graph of workflow steps.
+package dag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+// CycleError dependOns 中存在环
+type CycleError struct {
+	// 环上的step，首尾相同，比如 [a b a]
+	Cycle []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("dependOns contains a cycle: %s", strings.Join(e.Cycle, " -> "))
+}
+
+// Unsatisfiable 描述一个永远无法满足的依赖
+type Unsatisfiable struct {
+	Step     string
+	DependOn v1alpha1.DependOn
+	Reason   string
+	// Skipped 处理失败的step 依赖无法满足时会被跳过，不算spec 错误
+	Skipped bool
+}
+
+func (u Unsatisfiable) String() string {
+	return fmt.Sprintf("step %s depends on %s of step %s, %s", u.Step, u.DependOn.Phase, u.DependOn.Name, u.Reason)
+}
+
+// UnsatisfiableError workflow 中存在永远无法满足的依赖，相关step 永远不会运行
+type UnsatisfiableError struct {
+	Unsatisfiables []Unsatisfiable
+}
+
+func (e *UnsatisfiableError) Error() string {
+	msgs := make([]string, 0, len(e.Unsatisfiables))
+	for _, u := range e.Unsatisfiables {
+		msgs = append(msgs, u.String())
+	}
+	return fmt.Sprintf("dependOns can never be satisfied: %s", strings.Join(msgs, "; "))
+}
+
+// failurePhases 依赖这些phase 的step 用于处理失败，比如清理资源
+var failurePhases = []v1alpha1.StepPhase{v1alpha1.StepRollBacked, v1alpha1.StepFailed}
+
+// runnablePhases workflow Running 时可以依赖的phase，RollingBack 只是中间状态，不能依赖
+var runnablePhases = append([]v1alpha1.StepPhase{v1alpha1.StepPending, v1alpha1.StepRunning, v1alpha1.StepSuccess, v1alpha1.StepSkipped},
+	failurePhases...)
+
+// reachablePhases workflow Running 时step 从当前phase 还能进入的phase
+func reachablePhases(phase v1alpha1.StepPhase, ws v1alpha1.WorkflowStep) []v1alpha1.StepPhase {
+	switch phase {
+	case "", v1alpha1.StepPending:
+		if skippable(ws) {
+			return runnablePhases
+		}
+		return append([]v1alpha1.StepPhase{v1alpha1.StepPending, v1alpha1.StepRunning, v1alpha1.StepSuccess}, failurePhases...)
+	case v1alpha1.StepRunning:
+		return append([]v1alpha1.StepPhase{v1alpha1.StepRunning, v1alpha1.StepSuccess}, failurePhases...)
+	case v1alpha1.StepRollingBack:
+		return failurePhases
+	case v1alpha1.StepSuccess, v1alpha1.StepSkipped, v1alpha1.StepRollBacked, v1alpha1.StepFailed:
+		return []v1alpha1.StepPhase{phase}
+	}
+	return nil
+}
+
+// HandlesFailure step 依赖了其他step 的RollBacked/Failed，用于处理失败。
+// workflow 会等它运行结束后才失败或回滚，依赖无法满足时step 会被跳过
+func HandlesFailure(ws v1alpha1.WorkflowStep) bool {
+	for _, dependOn := range ws.DependOns {
+		if containsPhase(failurePhases, dependOn.Phase) {
+			return true
+		}
+	}
+	return false
+}
+
+// skippable 设置了when，withParam 展开后为空，或者处理失败的step 会被跳过
+func skippable(ws v1alpha1.WorkflowStep) bool {
+	return len(ws.When) > 0 || len(ws.WithParam) > 0 || HandlesFailure(ws)
+}
+
+func containsPhase(phases []v1alpha1.StepPhase, phase v1alpha1.StepPhase) bool {
+	for _, p := range phases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// canEnter 依赖Success 时，依赖step 被跳过也视为满足
+func canEnter(reachable []v1alpha1.StepPhase, phase v1alpha1.StepPhase) bool {
+	if phase == v1alpha1.StepSuccess && containsPhase(reachable, v1alpha1.StepSkipped) {
+		return true
+	}
+	return containsPhase(reachable, phase)
+}
+
+func isStarted(phase v1alpha1.StepPhase) bool {
+	return phase != "" && phase != v1alpha1.StepPending
+}
+
+// dependOnNames step 依赖的其他step，忽略依赖自身以及不存在的step
+func dependOnNames(steps []v1alpha1.WorkflowStep) map[string][]string {
+	exists := map[string]bool{}
+	for _, ws := range steps {
+		exists[ws.Name] = true
+	}
+	dependOns := map[string][]string{}
+	for _, ws := range steps {
+		for _, dependOn := range ws.DependOns {
+			if dependOn.Name != ws.Name && exists[dependOn.Name] {
+				dependOns[ws.Name] = append(dependOns[ws.Name], dependOn.Name)
+			}
+		}
+	}
+	return dependOns
+}
+
+// Upstream 返回step 直接或间接依赖的step
+func Upstream(steps []v1alpha1.WorkflowStep, name string) map[string]bool {
+	dependOns := dependOnNames(steps)
+	upstream := map[string]bool{}
+	var visit func(string)
+	visit = func(name string) {
+		for _, dependOn := range dependOns[name] {
+			if !upstream[dependOn] {
+				upstream[dependOn] = true
+				visit(dependOn)
+			}
+		}
+	}
+	visit(name)
+	return upstream
+}
+
+// FindCycle 返回dependOns 中的一个环，比如 [a b a]，没有环时返回nil
+func FindCycle(steps []v1alpha1.WorkflowStep) []string {
+	dependOns := dependOnNames(steps)
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := map[string]int{}
+	var path []string
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visiting:
+			for i := range path {
+				if path[i] == name {
+					return append(append([]string{}, path[i:]...), name)
+				}
+			}
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dependOn := range dependOns[name] {
+			if cycle := visit(dependOn); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+	for _, ws := range steps {
+		if cycle := visit(ws.Name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// TopologicalSort 按依赖顺序返回step 名称，被依赖的step 在前，同一层按spec 中的顺序，存在环时返回CycleError
+func TopologicalSort(steps []v1alpha1.WorkflowStep) ([]string, error) {
+	dependOns := dependOnNames(steps)
+	index := map[string]int{}
+	for i, ws := range steps {
+		index[ws.Name] = i
+	}
+	inDegree := map[string]int{}
+	reverseDependOns := map[string][]string{}
+	for _, ws := range steps {
+		inDegree[ws.Name] += 0
+		for _, dependOn := range dependOns[ws.Name] {
+			inDegree[ws.Name]++
+			reverseDependOns[dependOn] = append(reverseDependOns[dependOn], ws.Name)
+		}
+	}
+	ready := make([]string, 0)
+	for _, ws := range steps {
+		if inDegree[ws.Name] == 0 {
+			ready = append(ready, ws.Name)
+		}
+	}
+	order := make([]string, 0, len(inDegree))
+	for len(ready) > 0 {
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+		next := make([]string, 0)
+		for _, reverseDependOn := range reverseDependOns[name] {
+			inDegree[reverseDependOn]--
+			if inDegree[reverseDependOn] == 0 {
+				next = append(next, reverseDependOn)
+			}
+		}
+		sort.Slice(next, func(i, j int) bool { return index[next[i]] < index[next[j]] })
+		ready = append(ready, next...)
+	}
+	if len(order) < len(inDegree) {
+		return order, &CycleError{Cycle: FindCycle(steps)}
+	}
+	return order, nil
+}
+
+// FindUnsatisfiable 找出workflow Running 时永远无法满足的依赖，phases 为各step 当前的phase，为空表示都还没有运行。
+// 依赖step 不存在、依赖RollingBack，或者依赖step 已经越过了依赖的phase，都无法满足；
+// 无法运行的step 会一直处于Pending，依赖它的step 也可能因此无法满足；处理失败的step 则会被跳过。
+// dependMode 为Any 时所有dependOn 都无法满足才算无法满足，为Expression 时只检查依赖step 是否存在。
+func FindUnsatisfiable(steps []v1alpha1.WorkflowStep, phases map[string]v1alpha1.StepPhase) []Unsatisfiable {
+	order, err := TopologicalSort(steps)
+	if err != nil {
+		return nil
+	}
+	workflowSteps := map[string]v1alpha1.WorkflowStep{}
+	for _, ws := range steps {
+		workflowSteps[ws.Name] = ws
+	}
+	// 每个step 还能进入的phase
+	reachable := map[string][]v1alpha1.StepPhase{}
+	// 依赖无法满足、永远不会运行的step
+	blocked := map[string]bool{}
+	ret := make([]Unsatisfiable, 0)
+	for _, name := range order {
+		phase := phases[name]
+		if isStarted(phase) {
+			reachable[name] = reachablePhases(phase, workflowSteps[name])
+			continue
+		}
+		ws := workflowSteps[name]
+		reasons := map[v1alpha1.DependOn]string{}
+		for _, dependOn := range ws.DependOns {
+			if reason := unsatisfiableReason(name, dependOn, ws.DependMode, reachable, blocked); reason != "" {
+				reasons[dependOn] = reason
+			}
+		}
+		satisfiable, _ := Satisfied(ws, func(dependOn v1alpha1.DependOn) bool { return reasons[dependOn] == "" })
+		// dependExpression 中可以取反，只要求依赖step 存在
+		if ws.DependMode == v1alpha1.DependExpression {
+			satisfiable = len(reasons) == 0
+		}
+		if satisfiable {
+			reachable[name] = reachablePhases("", ws)
+			continue
+		}
+		// 依赖的step 都存在时，处理失败的step 会被跳过
+		skipped := HandlesFailure(ws)
+		for _, dependOn := range ws.DependOns {
+			if reason := reasons[dependOn]; reason != "" && !reachableReason(name, dependOn, reachable) {
+				skipped = false
+			}
+		}
+		for _, dependOn := range ws.DependOns {
+			if reason := reasons[dependOn]; reason != "" {
+				ret = append(ret, Unsatisfiable{Step: name, DependOn: dependOn, Reason: reason, Skipped: skipped})
+			}
+		}
+		if skipped {
+			reachable[name] = []v1alpha1.StepPhase{v1alpha1.StepSkipped}
+			continue
+		}
+		// 无法运行的step 一直处于Pending
+		reachable[name] = []v1alpha1.StepPhase{v1alpha1.StepPending}
+		blocked[name] = true
+	}
+	return ret
+}
+
+// unsatisfiableReason 返回dependOn 永远无法满足的原因，可以满足时返回空
+func unsatisfiableReason(name string, dependOn v1alpha1.DependOn, mode v1alpha1.DependMode,
+	reachable map[string][]v1alpha1.StepPhase, blocked map[string]bool) string {
+	dependOnReachable, ok := reachable[dependOn.Name]
+	switch {
+	case dependOn.Name == name:
+		return "step can not depend on itself"
+	case !ok:
+		return "step does not exist"
+	case mode == v1alpha1.DependExpression:
+		return ""
+	case !containsPhase(runnablePhases, dependOn.Phase):
+		return fmt.Sprintf("%s is a transient phase", dependOn.Phase)
+	case blocked[dependOn.Name] && !canEnter(dependOnReachable, dependOn.Phase):
+		return fmt.Sprintf("step %s will never run", dependOn.Name)
+	case !canEnter(dependOnReachable, dependOn.Phase):
+		return fmt.Sprintf("step %s can not enter %s any more", dependOn.Name, dependOn.Phase)
+	}
+	return ""
+}
+
+// reachableReason dependOn 是因为依赖step 不能再进入依赖的phase 而无法满足
+func reachableReason(name string, dependOn v1alpha1.DependOn, reachable map[string][]v1alpha1.StepPhase) bool {
+	_, ok := reachable[dependOn.Name]
+	return dependOn.Name != name && ok && containsPhase(runnablePhases, dependOn.Phase)
+}
+
+// Analyze 检查dependOns 是否存在环、dependExpression 是否正确以及是否存在永远无法满足的依赖，处理失败的step 被跳过不算错误
+func Analyze(steps []v1alpha1.WorkflowStep, phases map[string]v1alpha1.StepPhase) error {
+	if _, err := TopologicalSort(steps); err != nil {
+		return err
+	}
+	for _, ws := range steps {
+		if err := ValidateDependExpression(ws); err != nil {
+			return fmt.Errorf("step %s has invalid dependExpression: %v", ws.Name, err)
+		}
+	}
+	unsatisfiables := make([]Unsatisfiable, 0)
+	for _, u := range FindUnsatisfiable(steps, phases) {
+		if !u.Skipped {
+			unsatisfiables = append(unsatisfiables, u)
+		}
+	}
+	if len(unsatisfiables) > 0 {
+		return &UnsatisfiableError{Unsatisfiables: unsatisfiables}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package dag
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+// newStep dependOns 格式为 name 或 name:phase，phase 默认为Success
+func newStep(name string, dependOns ...string) v1alpha1.WorkflowStep {
+	ws := v1alpha1.WorkflowStep{Name: name}
+	for _, dependOn := range dependOns {
+		phase := v1alpha1.StepSuccess
+		if i := strings.Index(dependOn, ":"); i >= 0 {
+			dependOn, phase = dependOn[:i], v1alpha1.StepPhase(dependOn[i+1:])
+		}
+		ws.DependOns = append(ws.DependOns, v1alpha1.DependOn{Name: dependOn, Phase: phase})
+	}
+	return ws
+}
+
+func withWhen(ws v1alpha1.WorkflowStep, when string) v1alpha1.WorkflowStep {
+	ws.When = when
+	return ws
+}
+
+func TestTopologicalSort(t *testing.T) {
+	steps := []v1alpha1.WorkflowStep{
+		newStep("d", "b", "c"),
+		newStep("c", "a"),
+		newStep("b", "a"),
+		newStep("a"),
+		newStep("e"),
+	}
+	order, err := TopologicalSort(steps)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if expect := []string{"a", "e", "c", "b", "d"}; !reflect.DeepEqual(order, expect) {
+		t.Errorf("expect %v, got %v", expect, order)
+	}
+}
+
+func TestTopologicalSortCycle(t *testing.T) {
+	steps := []v1alpha1.WorkflowStep{
+		newStep("a"),
+		newStep("b", "a", "d"),
+		newStep("c", "b"),
+		newStep("d", "c"),
+	}
+	_, err := TopologicalSort(steps)
+	var cycleErr *CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("expect CycleError, got %v", err)
+	}
+	if expect := []string{"b", "d", "c", "b"}; !reflect.DeepEqual(cycleErr.Cycle, expect) {
+		t.Errorf("expect cycle %v, got %v", expect, cycleErr.Cycle)
+	}
+}
+
+func TestFindUnsatisfiable(t *testing.T) {
+	cases := []struct {
+		name   string
+		steps  []v1alpha1.WorkflowStep
+		phases map[string]v1alpha1.StepPhase
+		// 依赖无法满足的step
+		expect []string
+	}{
+		{
+			name:   "satisfiable",
+			steps:  []v1alpha1.WorkflowStep{newStep("a"), newStep("b", "a"), newStep("c", "a:Running", "b:Pending")},
+			expect: []string{},
+		},
+		{
+			name:   "depend on nonexistent step",
+			steps:  []v1alpha1.WorkflowStep{newStep("a", "x")},
+			expect: []string{"a"},
+		},
+		{
+			name:   "depend on rolling back phase",
+			steps:  []v1alpha1.WorkflowStep{newStep("a"), newStep("b", "a:RollingBack")},
+			expect: []string{"b"},
+		},
+		{
+			name:   "depend on failed phase",
+			steps:  []v1alpha1.WorkflowStep{newStep("a"), newStep("b", "a:Failed"), newStep("c", "a:RollBacked")},
+			phases: map[string]v1alpha1.StepPhase{"a": v1alpha1.StepRollingBack},
+			expect: []string{},
+		},
+		{
+			name:   "depend on a step that will never run",
+			steps:  []v1alpha1.WorkflowStep{newStep("a"), newStep("b", "a:RollingBack"), newStep("c", "b"), newStep("d", "b:Pending")},
+			expect: []string{"b", "c"},
+		},
+		{
+			name:   "dependency already succeeded",
+			steps:  []v1alpha1.WorkflowStep{newStep("a"), newStep("b", "a:Running"), newStep("c", "a")},
+			phases: map[string]v1alpha1.StepPhase{"a": v1alpha1.StepSuccess},
+			expect: []string{"b"},
+		},
+		{
+			name:   "dependency already started",
+			steps:  []v1alpha1.WorkflowStep{newStep("a"), newStep("b", "a:Pending")},
+			phases: map[string]v1alpha1.StepPhase{"a": v1alpha1.StepRunning},
+			expect: []string{"b"},
+		},
+		{
+			name:   "started step is not checked",
+			steps:  []v1alpha1.WorkflowStep{newStep("a"), newStep("b", "a:Running")},
+			phases: map[string]v1alpha1.StepPhase{"a": v1alpha1.StepSuccess, "b": v1alpha1.StepSuccess},
+			expect: []string{},
+		},
+		{
+			name:   "skipped dependency satisfies success",
+			steps:  []v1alpha1.WorkflowStep{newStep("a"), newStep("b", "a")},
+			phases: map[string]v1alpha1.StepPhase{"a": v1alpha1.StepSkipped},
+			expect: []string{},
+		},
+		{
+			name:   "step without when will never be skipped",
+			steps:  []v1alpha1.WorkflowStep{newStep("a"), newStep("b", "a:Skipped"), withWhen(newStep("c"), "[parameters.env] == 'prod'"), newStep("d", "c:Skipped")},
+			expect: []string{"b"},
+		},
+		{
+			name:   "withParam step may be skipped",
+			steps:  []v1alpha1.WorkflowStep{{Name: "a", WithParam: "attributes.regions"}, newStep("b", "a:Skipped")},
+			expect: []string{},
+		},
+		{
+			name:   "skipped dependency can not run any more",
+			steps:  []v1alpha1.WorkflowStep{withWhen(newStep("a"), "[parameters.env] == 'prod'"), newStep("b", "a:Running")},
+			phases: map[string]v1alpha1.StepPhase{"a": v1alpha1.StepSkipped},
+			expect: []string{"b"},
+		},
+	}
+	for _, c := range cases {
+		got := make([]string, 0)
+		for _, u := range FindUnsatisfiable(c.steps, c.phases) {
+			got = append(got, u.Step)
+		}
+		if !reflect.DeepEqual(got, c.expect) {
+			t.Errorf("%s: expect %v, got %v", c.name, c.expect, got)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	if err := Analyze([]v1alpha1.WorkflowStep{newStep("a"), newStep("b", "a")}, nil); err != nil {
+		t.Errorf("expect no error, got %v", err)
+	}
+	err := Analyze([]v1alpha1.WorkflowStep{newStep("a", "b"), newStep("b", "a")}, nil)
+	if err == nil || !strings.Contains(err.Error(), "a -> b -> a") {
+		t.Errorf("expect cycle error, got %v", err)
+	}
+	err = Analyze([]v1alpha1.WorkflowStep{newStep("a"), newStep("b", "a:RollingBack")}, nil)
+	if err == nil || !strings.Contains(err.Error(), "step b depends on RollingBack of step a") {
+		t.Errorf("expect unsatisfiable error, got %v", err)
+	}
+}
+
+func TestFindUnsatisfiableHandlesFailure(t *testing.T) {
+	// a 或b 失败时运行c 清理资源
+	steps := []v1alpha1.WorkflowStep{newStep("a"), newStep("b"),
+		withDependMode(newStep("c", "a:Failed", "b:Failed"), v1alpha1.DependAny, ""), newStep("d", "c")}
+	cases := []struct {
+		name   string
+		phases map[string]v1alpha1.StepPhase
+		// 依赖无法满足的step
+		expect []string
+	}{
+		{
+			name:   "not started",
+			expect: []string{},
+		},
+		{
+			name:   "b may fail",
+			phases: map[string]v1alpha1.StepPhase{"a": v1alpha1.StepSuccess, "b": v1alpha1.StepRunning},
+			expect: []string{},
+		},
+		{
+			name:   "a failed",
+			phases: map[string]v1alpha1.StepPhase{"a": v1alpha1.StepFailed, "b": v1alpha1.StepSuccess},
+			expect: []string{},
+		},
+		{
+			name:   "nothing failed",
+			phases: map[string]v1alpha1.StepPhase{"a": v1alpha1.StepSuccess, "b": v1alpha1.StepRollBacked},
+			expect: []string{"c", "c"},
+		},
+	}
+	for _, c := range cases {
+		got := make([]string, 0)
+		for _, u := range FindUnsatisfiable(steps, c.phases) {
+			got = append(got, u.Step)
+			if !u.Skipped {
+				t.Errorf("%s: expect %s skipped", c.name, u)
+			}
+		}
+		if !reflect.DeepEqual(got, c.expect) {
+			t.Errorf("%s: expect %v, got %v", c.name, c.expect, got)
+		}
+		// c 被跳过时d 仍然可以运行
+		if err := Analyze(steps, c.phases); err != nil {
+			t.Errorf("%s: expect no error, got %v", c.name, err)
+		}
+	}
+}
+
+func TestUpstream(t *testing.T) {
+	steps := []v1alpha1.WorkflowStep{newStep("a"), newStep("b", "a"), newStep("c", "b"), newStep("d")}
+	upstream := Upstream(steps, "c")
+	if len(upstream) != 2 || !upstream["a"] || !upstream["b"] {
+		t.Errorf("expect a and b upstream of c, got %v", upstream)
+	}
+}
@@ -0,0 +1,68 @@
+package dag
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"github.com/qiankunli/workflow/pkg/expression"
+)
+
+// Satisfied 按step 的dependMode 判断dependOns 是否满足，matched 判断单个dependOn 是否满足，同时返回已满足的依赖step。
+// 同一个step 在dependOns 中出现多次时，全部满足才算该step 满足
+func Satisfied(ws v1alpha1.WorkflowStep, matched func(dependOn v1alpha1.DependOn) bool) (bool, []string) {
+	if len(ws.DependOns) == 0 {
+		return true, nil
+	}
+	names := make([]string, 0, len(ws.DependOns))
+	values := map[string]bool{}
+	for _, dependOn := range ws.DependOns {
+		value, ok := values[dependOn.Name]
+		if !ok {
+			names = append(names, dependOn.Name)
+		}
+		values[dependOn.Name] = (!ok || value) && matched(dependOn)
+	}
+	satisfiedNames := make([]string, 0, len(names))
+	for _, name := range names {
+		if values[name] {
+			satisfiedNames = append(satisfiedNames, name)
+		}
+	}
+	switch ws.DependMode {
+	case v1alpha1.DependAny:
+		return len(satisfiedNames) > 0, satisfiedNames
+	case v1alpha1.DependExpression:
+		variables := map[string]string{}
+		for name, value := range values {
+			variables[name] = strconv.FormatBool(value)
+		}
+		ok, err := expression.EvaluateBool(ws.DependExpression, variables)
+		return err == nil && ok, satisfiedNames
+	}
+	return len(satisfiedNames) == len(names), satisfiedNames
+}
+
+// ValidateDependExpression dependMode 为Expression 时，检查dependExpression 的语法，以及引用的都是dependOns 中的step
+func ValidateDependExpression(ws v1alpha1.WorkflowStep) error {
+	if ws.DependMode != v1alpha1.DependExpression {
+		return nil
+	}
+	if len(ws.DependExpression) == 0 {
+		return fmt.Errorf("dependExpression is required when dependMode is %s", v1alpha1.DependExpression)
+	}
+	variables, err := expression.Variables(ws.DependExpression)
+	if err != nil {
+		return err
+	}
+	dependOns := map[string]bool{}
+	for _, dependOn := range ws.DependOns {
+		dependOns[dependOn.Name] = true
+	}
+	for _, variable := range variables {
+		if !dependOns[variable] {
+			return fmt.Errorf("%s is not in dependOns", variable)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package dag
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+)
+
+func withDependMode(ws v1alpha1.WorkflowStep, mode v1alpha1.DependMode, expression string) v1alpha1.WorkflowStep {
+	ws.DependMode = mode
+	ws.DependExpression = expression
+	return ws
+}
+
+func TestSatisfied(t *testing.T) {
+	// 只有b 满足
+	matched := func(dependOn v1alpha1.DependOn) bool { return dependOn.Name == "b" }
+	cases := []struct {
+		name          string
+		ws            v1alpha1.WorkflowStep
+		expect        bool
+		expectMatched []string
+	}{
+		{name: "no dependOns", ws: newStep("x"), expect: true},
+		{name: "all", ws: newStep("x", "a", "b"), expect: false, expectMatched: []string{"b"}},
+		{name: "any", ws: withDependMode(newStep("x", "a", "b"), v1alpha1.DependAny, ""), expect: true, expectMatched: []string{"b"}},
+		{name: "expression", ws: withDependMode(newStep("x", "a", "b", "c"), v1alpha1.DependExpression, "(a || b) && !c"), expect: true, expectMatched: []string{"b"}},
+		{name: "duplicate dependOn", ws: withDependMode(newStep("x", "a", "b", "b:Running"), v1alpha1.DependAny, ""), expect: true, expectMatched: []string{"b"}},
+	}
+	for _, c := range cases {
+		satisfied, names := Satisfied(c.ws, matched)
+		if satisfied != c.expect {
+			t.Errorf("%s: expect %v, got %v", c.name, c.expect, satisfied)
+		}
+		if len(c.expectMatched) > 0 && !reflect.DeepEqual(names, c.expectMatched) {
+			t.Errorf("%s: expect matched %v, got %v", c.name, c.expectMatched, names)
+		}
+	}
+}
+
+func TestValidateDependExpression(t *testing.T) {
+	if err := ValidateDependExpression(withDependMode(newStep("x", "a", "step-b"), v1alpha1.DependExpression, "a || [step-b]")); err != nil {
+		t.Errorf("expect no error, got %v", err)
+	}
+	if err := ValidateDependExpression(withDependMode(newStep("x", "a"), v1alpha1.DependExpression, "a || c")); err == nil {
+		t.Errorf("expect error for step not in dependOns")
+	}
+	if err := ValidateDependExpression(withDependMode(newStep("x", "a"), v1alpha1.DependExpression, "")); err == nil {
+		t.Errorf("expect error for empty dependExpression")
+	}
+}
+
+func TestFindUnsatisfiableDependMode(t *testing.T) {
+	cases := []struct {
+		name   string
+		steps  []v1alpha1.WorkflowStep
+		phases map[string]v1alpha1.StepPhase
+		expect []string
+	}{
+		{
+			name:   "any with one satisfiable dependOn",
+			steps:  []v1alpha1.WorkflowStep{newStep("a"), newStep("b"), withDependMode(newStep("c", "a:Failed", "b"), v1alpha1.DependAny, "")},
+			expect: []string{},
+		},
+		{
+			name:   "any with no satisfiable dependOn",
+			steps:  []v1alpha1.WorkflowStep{newStep("a"), newStep("b"), withDependMode(newStep("c", "a:Failed", "b:Running"), v1alpha1.DependAny, "")},
+			phases: map[string]v1alpha1.StepPhase{"a": v1alpha1.StepSuccess, "b": v1alpha1.StepSuccess},
+			expect: []string{"c", "c"},
+		},
+		{
+			name:   "expression only checks dependOns exist",
+			steps:  []v1alpha1.WorkflowStep{newStep("a"), withDependMode(newStep("b", "a:Running"), v1alpha1.DependExpression, "!a")},
+			phases: map[string]v1alpha1.StepPhase{"a": v1alpha1.StepSuccess},
+			expect: []string{},
+		},
+		{
+			name:   "expression depends on nonexistent step",
+			steps:  []v1alpha1.WorkflowStep{newStep("a"), withDependMode(newStep("b", "a", "x"), v1alpha1.DependExpression, "a || x")},
+			expect: []string{"b"},
+		},
+	}
+	for _, c := range cases {
+		got := make([]string, 0)
+		for _, u := range FindUnsatisfiable(c.steps, c.phases) {
+			got = append(got, u.Step)
+		}
+		if !reflect.DeepEqual(got, c.expect) {
+			t.Errorf("%s: expect %v, got %v", c.name, c.expect, got)
+		}
+	}
+}
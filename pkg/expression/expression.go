@@ -0,0 +1,91 @@
+// Package expression evaluates the boolean expressions used in workflow spec, such as the `when` of a step.
+package expression
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/Knetic/govaluate"
+)
+
+// Validate 检查表达式语法是否正确
+func Validate(expression string) error {
+	_, err := govaluate.NewEvaluableExpression(expression)
+	return err
+}
+
+// Variables 返回表达式中引用的变量
+func Variables(expression string) ([]string, error) {
+	expr, err := govaluate.NewEvaluableExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	return expr.Vars(), nil
+}
+
+// EvaluateBool 计算表达式的值，表达式的结果必须是bool。
+// variables 中的true/false 转为bool，和数字比较、参与大小比较或算术运算的变量转为float64，其余都是string，
+// 所以 `[parameters.version] == '1.10'` 按字符串比较。表达式中引用的变量必须存在。
+// 变量名包含`.` 时需要用中括号括起来，比如 `[parameters.env] == 'prod'`
+func EvaluateBool(expression string, variables map[string]string) (bool, error) {
+	expr, err := govaluate.NewEvaluableExpression(expression)
+	if err != nil {
+		return false, err
+	}
+	numeric := numericVariables(expr.Tokens())
+	parameters := map[string]interface{}{}
+	for _, name := range expr.Vars() {
+		value, ok := variables[name]
+		if !ok {
+			return false, fmt.Errorf("variable %s not found", name)
+		}
+		parameters[name] = convert(value, numeric[name])
+	}
+	result, err := expr.Evaluate(parameters)
+	if err != nil {
+		return false, err
+	}
+	ret, ok := result.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression result %v is not bool", result)
+	}
+	return ret, nil
+}
+
+// numericOperators 两边都必须是数字的运算符
+var numericOperators = map[interface{}]bool{
+	">": true, ">=": true, "<": true, "<=": true, "-": true, "*": true, "/": true, "%": true, "**": true,
+}
+
+// numericVariables 和数字比较、参与大小比较或算术运算的变量
+func numericVariables(tokens []govaluate.ExpressionToken) map[string]bool {
+	ret := map[string]bool{}
+	for i := 1; i < len(tokens)-1; i++ {
+		if tokens[i].Kind != govaluate.COMPARATOR && tokens[i].Kind != govaluate.MODIFIER {
+			continue
+		}
+		left, right := tokens[i-1], tokens[i+1]
+		if left.Kind == govaluate.VARIABLE && (numericOperators[tokens[i].Value] || right.Kind == govaluate.NUMERIC) {
+			ret[left.Value.(string)] = true
+		}
+		if right.Kind == govaluate.VARIABLE && (numericOperators[tokens[i].Value] || left.Kind == govaluate.NUMERIC) {
+			ret[right.Value.(string)] = true
+		}
+	}
+	return ret
+}
+
+// convert numeric 为true 时把数字转为float64，NaN、Inf 不算数字
+func convert(value string, numeric bool) interface{} {
+	if value == "true" || value == "false" {
+		return value == "true"
+	}
+	if !numeric {
+		return value
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return f
+	}
+	return value
+}
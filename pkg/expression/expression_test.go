@@ -0,0 +1,68 @@
+package expression
+
+import (
+	"testing"
+)
+
+func TestEvaluateBool(t *testing.T) {
+	variables := map[string]string{
+		"parameters.env":       "prod",
+		"parameters.replicas":  "3",
+		"attributes.migration": "true",
+		"parameters.version":   "1.10",
+		"parameters.id":        "007",
+		"parameters.ratio":     "nan",
+		"parameters.limit":     "inf",
+	}
+	cases := []struct {
+		expression string
+		expected   bool
+		wantErr    bool
+	}{
+		{expression: "[parameters.env] == 'prod'", expected: true},
+		{expression: "[parameters.env] == 'test'", expected: false},
+		{expression: "[parameters.replicas] > 2", expected: true},
+		{expression: "[attributes.migration]", expected: true},
+		{expression: "[attributes.migration] && [parameters.env] != 'prod'", expected: false},
+		{expression: "[parameters.replicas] == 3", expected: true},
+		{expression: "[parameters.replicas] * 2 == 6", expected: true},
+		{expression: "[parameters.replicas] == '3'", expected: true},
+		// 不和数字比较时按字符串比较
+		{expression: "[parameters.version] == '1.10'", expected: true},
+		{expression: "[parameters.version] == '1.1'", expected: false},
+		{expression: "[parameters.version] == 1.1", expected: true},
+		{expression: "[parameters.id] == '007'", expected: true},
+		{expression: "[parameters.id] == [parameters.version]", expected: false},
+		{expression: "[parameters.ratio] == 'nan'", expected: true},
+		{expression: "[parameters.limit] == 'inf'", expected: true},
+		{expression: "[parameters.limit] > 1", wantErr: true},
+		{expression: "[parameters.missing] == 'a'", wantErr: true},
+		{expression: "[parameters.env]", wantErr: true},
+		{expression: "[parameters.env] ==", wantErr: true},
+	}
+	for _, c := range cases {
+		actual, err := EvaluateBool(c.expression, variables)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error, got nil", c.expression)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", c.expression, err)
+			continue
+		}
+		if actual != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.expression, c.expected, actual)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate("[parameters.env] == 'prod'"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := Validate("[parameters.env] == 'prod' &&"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
@@ -0,0 +1,42 @@
+package expression
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// templatePattern 匹配 {{workflow.parameters.region}} 这样的模板表达式，花括号内可以有空格
+var templatePattern = regexp.MustCompile(`{{\s*([^{}\s]+)\s*}}`)
+
+// IsTemplate 字符串中是否包含模板表达式
+func IsTemplate(s string) bool {
+	return templatePattern.MatchString(s)
+}
+
+// TemplateVariables 返回字符串中模板表达式引用的变量
+func TemplateVariables(s string) []string {
+	variables := make([]string, 0)
+	for _, match := range templatePattern.FindAllStringSubmatch(s, -1) {
+		variables = append(variables, match[1])
+	}
+	return variables
+}
+
+// Render 用variables 替换字符串中的模板表达式，引用的变量必须存在
+func Render(s string, variables map[string]string) (string, error) {
+	missing := make([]string, 0)
+	ret := templatePattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := templatePattern.FindStringSubmatch(match)[1]
+		value, ok := variables[name]
+		if !ok {
+			missing = append(missing, name)
+			return match
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("variable %s not found", strings.Join(missing, ", "))
+	}
+	return ret, nil
+}
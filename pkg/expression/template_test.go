@@ -0,0 +1,45 @@
+package expression
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	variables := map[string]string{
+		"workflow.parameters.region":   "us-east",
+		"steps.step1.attributes.vpcId": "vpc-1",
+	}
+	cases := []struct {
+		template  string
+		expect    string
+		expectErr bool
+	}{
+		{template: "plain", expect: "plain"},
+		{template: "{{workflow.parameters.region}}", expect: "us-east"},
+		{template: "{{ steps.step1.attributes.vpcId }}/{{workflow.parameters.region}}", expect: "vpc-1/us-east"},
+		{template: "{{workflow.parameters.zone}}", expectErr: true},
+	}
+	for _, c := range cases {
+		actual, err := Render(c.template, variables)
+		if c.expectErr {
+			if err == nil {
+				t.Errorf("%s: expect error", c.template)
+			}
+			continue
+		}
+		if err != nil || actual != c.expect {
+			t.Errorf("%s: expect %s, got %s %v", c.template, c.expect, actual, err)
+		}
+	}
+}
+
+func TestTemplateVariables(t *testing.T) {
+	actual := TemplateVariables("{{ workflow.parameters.region }}-{{item}}")
+	if expect := []string{"workflow.parameters.region", "item"}; !reflect.DeepEqual(actual, expect) {
+		t.Errorf("expect %v, got %v", expect, actual)
+	}
+	if IsTemplate("{region}") {
+		t.Errorf("expect not a template")
+	}
+}
@@ -16,6 +16,18 @@ type FakeWorkflowV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeWorkflowV1alpha1) ClusterWorkflowTemplates() v1alpha1.ClusterWorkflowTemplateInterface {
+	return &FakeClusterWorkflowTemplates{c}
+}
+
+func (c *FakeWorkflowV1alpha1) CronWorkflows(namespace string) v1alpha1.CronWorkflowInterface {
+	return &FakeCronWorkflows{c, namespace}
+}
+
+func (c *FakeWorkflowV1alpha1) Queues() v1alpha1.QueueInterface {
+	return &FakeQueues{c}
+}
+
 func (c *FakeWorkflowV1alpha1) Steps(namespace string) v1alpha1.StepInterface {
 	return &FakeSteps{c, namespace}
 }
@@ -24,6 +36,10 @@ func (c *FakeWorkflowV1alpha1) Workflows(namespace string) v1alpha1.WorkflowInte
 	return &FakeWorkflows{c, namespace}
 }
 
+func (c *FakeWorkflowV1alpha1) WorkflowTemplates(namespace string) v1alpha1.WorkflowTemplateInterface {
+	return &FakeWorkflowTemplates{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeWorkflowV1alpha1) RESTClient() rest.Interface {
@@ -6,6 +6,14 @@ Copyright 2021 workflow authors. All rights reserved.
 
 package v1alpha1
 
+type ClusterWorkflowTemplateExpansion interface{}
+
+type CronWorkflowExpansion interface{}
+
+type QueueExpansion interface{}
+
 type StepExpansion interface{}
 
 type WorkflowExpansion interface{}
+
+type WorkflowTemplateExpansion interface{}
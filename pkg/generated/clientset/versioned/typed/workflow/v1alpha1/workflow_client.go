@@ -14,8 +14,12 @@ import (
 
 type WorkflowV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	ClusterWorkflowTemplatesGetter
+	CronWorkflowsGetter
+	QueuesGetter
 	StepsGetter
 	WorkflowsGetter
+	WorkflowTemplatesGetter
 }
 
 // WorkflowV1alpha1Client is used to interact with features provided by the workflow.example.com group.
@@ -23,6 +27,18 @@ type WorkflowV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *WorkflowV1alpha1Client) ClusterWorkflowTemplates() ClusterWorkflowTemplateInterface {
+	return newClusterWorkflowTemplates(c)
+}
+
+func (c *WorkflowV1alpha1Client) CronWorkflows(namespace string) CronWorkflowInterface {
+	return newCronWorkflows(c, namespace)
+}
+
+func (c *WorkflowV1alpha1Client) Queues() QueueInterface {
+	return newQueues(c)
+}
+
 func (c *WorkflowV1alpha1Client) Steps(namespace string) StepInterface {
 	return newSteps(c, namespace)
 }
@@ -31,6 +47,10 @@ func (c *WorkflowV1alpha1Client) Workflows(namespace string) WorkflowInterface {
 	return newWorkflows(c, namespace)
 }
 
+func (c *WorkflowV1alpha1Client) WorkflowTemplates(namespace string) WorkflowTemplateInterface {
+	return newWorkflowTemplates(c, namespace)
+}
+
 // NewForConfig creates a new WorkflowV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*WorkflowV1alpha1Client, error) {
 	config := *c
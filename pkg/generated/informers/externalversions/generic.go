@@ -41,10 +41,18 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=workflow.example.com, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("clusterworkflowtemplates"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Workflow().V1alpha1().ClusterWorkflowTemplates().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("cronworkflows"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Workflow().V1alpha1().CronWorkflows().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("queues"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Workflow().V1alpha1().Queues().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("steps"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Workflow().V1alpha1().Steps().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("workflows"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Workflow().V1alpha1().Workflows().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("workflowtemplates"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Workflow().V1alpha1().WorkflowTemplates().Informer()}, nil
 
 	}
 
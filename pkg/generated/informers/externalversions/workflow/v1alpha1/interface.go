@@ -12,10 +12,18 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// ClusterWorkflowTemplates returns a ClusterWorkflowTemplateInformer.
+	ClusterWorkflowTemplates() ClusterWorkflowTemplateInformer
+	// CronWorkflows returns a CronWorkflowInformer.
+	CronWorkflows() CronWorkflowInformer
+	// Queues returns a QueueInformer.
+	Queues() QueueInformer
 	// Steps returns a StepInformer.
 	Steps() StepInformer
 	// Workflows returns a WorkflowInformer.
 	Workflows() WorkflowInformer
+	// WorkflowTemplates returns a WorkflowTemplateInformer.
+	WorkflowTemplates() WorkflowTemplateInformer
 }
 
 type version struct {
@@ -29,6 +37,21 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// ClusterWorkflowTemplates returns a ClusterWorkflowTemplateInformer.
+func (v *version) ClusterWorkflowTemplates() ClusterWorkflowTemplateInformer {
+	return &clusterWorkflowTemplateInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// CronWorkflows returns a CronWorkflowInformer.
+func (v *version) CronWorkflows() CronWorkflowInformer {
+	return &cronWorkflowInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// Queues returns a QueueInformer.
+func (v *version) Queues() QueueInformer {
+	return &queueInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // Steps returns a StepInformer.
 func (v *version) Steps() StepInformer {
 	return &stepInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -38,3 +61,8 @@ func (v *version) Steps() StepInformer {
 func (v *version) Workflows() WorkflowInformer {
 	return &workflowInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// WorkflowTemplates returns a WorkflowTemplateInformer.
+func (v *version) WorkflowTemplates() WorkflowTemplateInformer {
+	return &workflowTemplateInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
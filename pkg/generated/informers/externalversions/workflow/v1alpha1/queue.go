@@ -0,0 +1,77 @@
+/*
+Copyright 2021 workflow authors. All rights reserved.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	time "time"
+
+	workflowv1alpha1 "github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	versioned "github.com/qiankunli/workflow/pkg/generated/clientset/versioned"
+	internalinterfaces "github.com/qiankunli/workflow/pkg/generated/informers/externalversions/internalinterfaces"
+	v1alpha1 "github.com/qiankunli/workflow/pkg/generated/listers/workflow/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// QueueInformer provides access to a shared informer and lister for
+// Queues.
+type QueueInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1alpha1.QueueLister
+}
+
+type queueInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewQueueInformer constructs a new informer for Queue type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewQueueInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredQueueInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredQueueInformer constructs a new informer for Queue type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredQueueInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.WorkflowV1alpha1().Queues().List(context.TODO(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.WorkflowV1alpha1().Queues().Watch(context.TODO(), options)
+			},
+		},
+		&workflowv1alpha1.Queue{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *queueInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredQueueInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *queueInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&workflowv1alpha1.Queue{}, f.defaultInformer)
+}
+
+func (f *queueInformer) Lister() v1alpha1.QueueLister {
+	return v1alpha1.NewQueueLister(f.Informer().GetIndexer())
+}
@@ -6,6 +6,22 @@ Copyright 2021 workflow authors. All rights reserved.
 
 package v1alpha1
 
+// ClusterWorkflowTemplateListerExpansion allows custom methods to be added to
+// ClusterWorkflowTemplateLister.
+type ClusterWorkflowTemplateListerExpansion interface{}
+
+// CronWorkflowListerExpansion allows custom methods to be added to
+// CronWorkflowLister.
+type CronWorkflowListerExpansion interface{}
+
+// CronWorkflowNamespaceListerExpansion allows custom methods to be added to
+// CronWorkflowNamespaceLister.
+type CronWorkflowNamespaceListerExpansion interface{}
+
+// QueueListerExpansion allows custom methods to be added to
+// QueueLister.
+type QueueListerExpansion interface{}
+
 // StepListerExpansion allows custom methods to be added to
 // StepLister.
 type StepListerExpansion interface{}
@@ -21,3 +37,11 @@ type WorkflowListerExpansion interface{}
 // WorkflowNamespaceListerExpansion allows custom methods to be added to
 // WorkflowNamespaceLister.
 type WorkflowNamespaceListerExpansion interface{}
+
+// WorkflowTemplateListerExpansion allows custom methods to be added to
+// WorkflowTemplateLister.
+type WorkflowTemplateListerExpansion interface{}
+
+// WorkflowTemplateNamespaceListerExpansion allows custom methods to be added to
+// WorkflowTemplateNamespaceLister.
+type WorkflowTemplateNamespaceListerExpansion interface{}
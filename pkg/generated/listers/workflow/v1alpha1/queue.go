@@ -0,0 +1,56 @@
+/*
+Copyright 2021 workflow authors. All rights reserved.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/qiankunli/workflow/pkg/apis/workflow/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// QueueLister helps list Queues.
+// All objects returned here must be treated as read-only.
+type QueueLister interface {
+	// List lists all Queues in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.Queue, err error)
+	// Get retrieves the Queue from the index for a given name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.Queue, error)
+	QueueListerExpansion
+}
+
+// queueLister implements the QueueLister interface.
+type queueLister struct {
+	indexer cache.Indexer
+}
+
+// NewQueueLister returns a new QueueLister.
+func NewQueueLister(indexer cache.Indexer) QueueLister {
+	return &queueLister{indexer: indexer}
+}
+
+// List lists all Queues in the indexer.
+func (s *queueLister) List(selector labels.Selector) (ret []*v1alpha1.Queue, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.Queue))
+	})
+	return ret, err
+}
+
+// Get retrieves the Queue from the index for a given name.
+func (s *queueLister) Get(name string) (*v1alpha1.Queue, error) {
+	obj, exists, err := s.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("queue"), name)
+	}
+	return obj.(*v1alpha1.Queue), nil
+}
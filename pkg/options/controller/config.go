@@ -16,6 +16,21 @@ const (
 type QueueConfig struct {
 	Strategy        QueueStrategy `json:"strategy"`
 	MaxRunningCount int           `json:"maxRunningCount"`
+	// pending workflow 每等待一个周期有效优先级加1，为0 表示不提升
+	PriorityAgingPeriod metav1.Duration `json:"priorityAgingPeriod"`
+}
+
+type WebhookConfig struct {
+	// 开启后controller 同时提供workflow、step 的defaulting/validating webhook
+	Enabled bool `json:"enabled"`
+	Port    int  `json:"port"`
+	// 存放tls.crt、tls.key 的目录
+	CertDir string `json:"certDir"`
+}
+
+type ArtifactConfig struct {
+	// 存放step artifact 的对象类型，ConfigMap 或Secret
+	Storage string `json:"storage"`
 }
 
 type StepConfig struct {
@@ -24,23 +39,39 @@ type StepConfig struct {
 	Qps         float64         `json:"qps"`
 	SyncTimeout metav1.Duration `json:"syncTimeout"`
 	Concurrency int             `json:"concurrency"`
+	// 单次 Run/Rollback/Sync 的超时时间，超时后 step 的ctx 会被cancel
+	Timeout metav1.Duration `json:"timeout"`
 }
 
 type Config struct {
 	SyncTimeout metav1.Duration `json:"syncTimeout"`
 	Concurrency int             `json:"concurrency"`
+	// StepConfig.Timeout 为空时使用
+	StepTimeout metav1.Duration `json:"stepTimeout"`
 	Steps       []StepConfig    `json:"steps"`
 	Queue       QueueConfig     `json:"queue"`
+	Webhook     WebhookConfig   `json:"webhook"`
+	Artifact    ArtifactConfig  `json:"artifact"`
 }
 
 func NewDefaultConfig() *Config {
 	opt := &Config{
 		SyncTimeout: metav1.Duration{Duration: 1 * time.Minute},
 		Concurrency: 30,
+		StepTimeout: metav1.Duration{Duration: 10 * time.Minute},
 		Steps:       []StepConfig{},
 		Queue: QueueConfig{
-			Strategy:        FIFO,
-			MaxRunningCount: 100,
+			Strategy:            FIFO,
+			MaxRunningCount:     100,
+			PriorityAgingPeriod: metav1.Duration{Duration: 5 * time.Minute},
+		},
+		Webhook: WebhookConfig{
+			Enabled: false,
+			Port:    9443,
+			CertDir: "/tmp/k8s-webhook-server/serving-certs",
+		},
+		Artifact: ArtifactConfig{
+			Storage: "ConfigMap",
 		},
 	}
 	return opt
@@ -0,0 +1,37 @@
+// Package schedule parses the cron schedule of CronWorkflow and computes its schedule times.
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// maxMissedSchedules 错过的调度次数超过该值时认为配置有误，比如controller 长时间没有运行或者schedule 过于频繁
+const maxMissedSchedules = 100
+
+// Parse 解析标准的5段cron 表达式，timezone 为IANA 时区，为空时使用本地时区
+func Parse(schedule, timezone string) (cron.Schedule, error) {
+	if len(timezone) > 0 {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("unknown timezone %s: %v", timezone, err)
+		}
+		schedule = fmt.Sprintf("CRON_TZ=%s %s", timezone, schedule)
+	}
+	return cron.ParseStandard(schedule)
+}
+
+// MostRecent 返回(earliest, now] 之间最近的一次调度时间，没有时返回零值
+func MostRecent(sched cron.Schedule, earliest, now time.Time) (time.Time, error) {
+	mostRecent := time.Time{}
+	missed := 0
+	for t := sched.Next(earliest); !t.After(now); t = sched.Next(t) {
+		mostRecent = t
+		missed++
+		if missed > maxMissedSchedules {
+			return time.Time{}, fmt.Errorf("too many missed schedules (> %d) since %s", maxMissedSchedules, earliest)
+		}
+	}
+	return mostRecent, nil
+}
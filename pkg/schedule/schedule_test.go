@@ -0,0 +1,46 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	sched, err := Parse("0 2 * * *", "Asia/Shanghai")
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	// 北京时间2点为UTC 18点
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	if next := sched.Next(now).UTC(); !next.Equal(time.Date(2023, 6, 1, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("expect next at 18:00 UTC, got %s", next)
+	}
+	if _, err = Parse("0 2 * * *", "Mars/Olympus"); err == nil {
+		t.Errorf("expect error for unknown timezone")
+	}
+	if _, err = Parse("every day", ""); err == nil {
+		t.Errorf("expect error for invalid schedule")
+	}
+}
+
+func TestMostRecent(t *testing.T) {
+	sched, _ := Parse("*/10 * * * *", "UTC")
+	earliest := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		now    time.Time
+		expect time.Time
+	}{
+		{now: earliest.Add(5 * time.Minute), expect: time.Time{}},
+		{now: earliest.Add(10 * time.Minute), expect: earliest.Add(10 * time.Minute)},
+		{now: earliest.Add(35 * time.Minute), expect: earliest.Add(30 * time.Minute)},
+	}
+	for _, c := range cases {
+		actual, err := MostRecent(sched, earliest, c.now)
+		if err != nil || !actual.Equal(c.expect) {
+			t.Errorf("now %s: expect %s, got %s %v", c.now, c.expect, actual, err)
+		}
+	}
+	if _, err := MostRecent(sched, earliest, earliest.Add(24*time.Hour)); err == nil {
+		t.Errorf("expect error for too many missed schedules")
+	}
+}
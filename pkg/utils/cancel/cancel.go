@@ -0,0 +1,65 @@
+package cancel
+
+import (
+	"context"
+	"sync"
+)
+
+// GroupCanceler manages cancel funcs of contexts, grouped by a group key.
+type GroupCanceler interface {
+	// Add registers cancel with key in group
+	Add(group, key string, cancel context.CancelFunc)
+	// Remove unregisters the cancel func with key in group, the cancel func is not called
+	Remove(group, key string)
+	// Cancel calls and unregisters all cancel funcs in group
+	Cancel(group string)
+}
+
+type groupCanceler struct {
+	m         sync.Mutex
+	cancelMap map[string]map[string]context.CancelFunc
+}
+
+// Add registers cancel with key in group.
+func (gc *groupCanceler) Add(group, key string, cancel context.CancelFunc) {
+	gc.m.Lock()
+	defer gc.m.Unlock()
+	cancels, ok := gc.cancelMap[group]
+	if !ok {
+		cancels = map[string]context.CancelFunc{}
+		gc.cancelMap[group] = cancels
+	}
+	cancels[key] = cancel
+}
+
+// Remove unregisters the cancel func with key in group.
+func (gc *groupCanceler) Remove(group, key string) {
+	gc.m.Lock()
+	defer gc.m.Unlock()
+	cancels, ok := gc.cancelMap[group]
+	if !ok {
+		return
+	}
+	delete(cancels, key)
+	if len(cancels) == 0 {
+		delete(gc.cancelMap, group)
+	}
+}
+
+// Cancel calls and unregisters all cancel funcs in group.
+func (gc *groupCanceler) Cancel(group string) {
+	gc.m.Lock()
+	cancels := gc.cancelMap[group]
+	delete(gc.cancelMap, group)
+	gc.m.Unlock()
+	for _, cancel := range cancels {
+		cancel()
+	}
+}
+
+// NewGroupCanceler returns a new group canceler.
+func NewGroupCanceler() GroupCanceler {
+	return &groupCanceler{
+		cancelMap: map[string]map[string]context.CancelFunc{},
+	}
+}
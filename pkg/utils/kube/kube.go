@@ -224,3 +224,78 @@ func DeleteSecretV2(ctx context.Context, c client.Client, namespace, name string
 	}
 	return nil
 }
+
+// GetConfigMapV2 returns value of key in data or binaryData of configmap by client.Client
+func GetConfigMapV2(ctx context.Context, c client.Client, namespace string, configmapRef *corev1.ConfigMapKeySelector) ([]byte, error) {
+	if configmapRef == nil {
+		return nil, errors.New("empty configmap ref")
+	}
+
+	configmap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: configmapRef.Name}, configmap); err != nil {
+		return nil, fmt.Errorf("failed to get configmap: %w", err)
+	}
+	if value, ok := configmap.Data[configmapRef.Key]; ok {
+		return []byte(value), nil
+	}
+	value, ok := configmap.BinaryData[configmapRef.Key]
+	if !ok {
+		return nil, fmt.Errorf("empty key %s in configmap %s", configmapRef.Key, configmapRef.Name)
+	}
+	return value, nil
+}
+
+// CreateOrUpdateConfigMapV2 create or update binary data of configmap by client.Client
+func CreateOrUpdateConfigMapV2(ctx context.Context, c client.Client, namespace string, configmapRef *corev1.ConfigMapKeySelector, data []byte, owners ...client.Object) error {
+	oldConfigMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: configmapRef.Name}, oldConfigMap); err != nil {
+		if !k8sapierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get configmap: %w", err)
+		}
+		ownerRefs := make([]metav1.OwnerReference, len(owners))
+		for i := range owners {
+			if namespace != owners[i].GetNamespace() {
+				return fmt.Errorf("owner namespace '%s' no equal to configmap namespace '%s'", owners[i].GetNamespace(), namespace)
+			}
+			ownerRefs[i] = *metav1.NewControllerRef(owners[i], owners[i].GetObjectKind().GroupVersionKind())
+		}
+		// not exist, create it
+		configmap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       namespace,
+				Name:            configmapRef.Name,
+				OwnerReferences: ownerRefs,
+			},
+			BinaryData: map[string][]byte{
+				configmapRef.Key: data,
+			},
+		}
+		if err = c.Create(ctx, configmap); err != nil {
+			return fmt.Errorf("failed to create configmap: %w", err)
+		}
+		return nil
+	}
+
+	if bytes.Equal(oldConfigMap.BinaryData[configmapRef.Key], data) {
+		return nil
+	}
+
+	if oldConfigMap.BinaryData == nil {
+		oldConfigMap.BinaryData = map[string][]byte{}
+	}
+	oldConfigMap.BinaryData[configmapRef.Key] = data
+	if err := c.Update(ctx, oldConfigMap); err != nil {
+		return fmt.Errorf("failed to update configmap: %w", err)
+	}
+	return nil
+}
+
+// DeleteConfigMapV2 delete configmap by client.Client
+func DeleteConfigMapV2(ctx context.Context, c client.Client, namespace, name string) error {
+	if err := c.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}); err != nil {
+		if !k8sapierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete configmap: %w", err)
+		}
+	}
+	return nil
+}
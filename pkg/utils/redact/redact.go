@@ -0,0 +1,119 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// Mask 替换敏感值的字符串
+const Mask = "******"
+
+// Redactor 把字符串中的敏感值替换为Mask
+type Redactor struct {
+	replacer *strings.Replacer
+}
+
+// New 忽略空值，为nil 时Redact 原样返回
+func New(values ...string) *Redactor {
+	filtered := make([]string, 0, len(values))
+	for _, v := range values {
+		if len(v) > 0 {
+			filtered = append(filtered, v)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+	// 先替换较长的值，避免一个值是另一个值的子串时只替换了一部分
+	sort.Slice(filtered, func(i, j int) bool { return len(filtered[i]) > len(filtered[j]) })
+	oldnew := make([]string, 0, 2*len(filtered))
+	for _, v := range filtered {
+		oldnew = append(oldnew, v, Mask)
+	}
+	return &Redactor{replacer: strings.NewReplacer(oldnew...)}
+}
+
+func (r *Redactor) Redact(s string) string {
+	if r == nil {
+		return s
+	}
+	return r.replacer.Replace(s)
+}
+
+// RedactMap 原地抹去map 中value 的敏感值
+func (r *Redactor) RedactMap(m map[string]string) {
+	if r == nil {
+		return
+	}
+	for k, v := range m {
+		m[k] = r.replacer.Replace(v)
+	}
+}
+
+// RedactJSON 抹去JSON 中字符串的敏感值，不是合法的JSON 时按字符串替换
+func (r *Redactor) RedactJSON(raw []byte) []byte {
+	if r == nil || len(raw) == 0 {
+		return raw
+	}
+	var v interface{}
+	// 保留数字的原样，避免大整数丢失精度
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return []byte(r.replacer.Replace(string(raw)))
+	}
+	redacted, err := json.Marshal(r.redactValue(v))
+	if err != nil {
+		return []byte(r.replacer.Replace(string(raw)))
+	}
+	return redacted
+}
+
+func (r *Redactor) redactValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case string:
+		return r.replacer.Replace(value)
+	case []interface{}:
+		for i := range value {
+			value[i] = r.redactValue(value[i])
+		}
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			redacted[r.replacer.Replace(k)] = r.redactValue(item)
+		}
+		return redacted
+	}
+	return v
+}
+
+// NewEventRecorder 发出的事件会先用redactor 抹去敏感值
+func NewEventRecorder(recorder record.EventRecorder, redactor *Redactor) record.EventRecorder {
+	if redactor == nil {
+		return recorder
+	}
+	return &eventRecorder{recorder: recorder, redactor: redactor}
+}
+
+type eventRecorder struct {
+	recorder record.EventRecorder
+	redactor *Redactor
+}
+
+func (e *eventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	e.recorder.Event(object, eventtype, reason, e.redactor.Redact(message))
+}
+
+func (e *eventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	e.recorder.Event(object, eventtype, reason, e.redactor.Redact(fmt.Sprintf(messageFmt, args...)))
+}
+
+func (e *eventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	e.recorder.AnnotatedEventf(object, annotations, eventtype, reason, "%s", e.redactor.Redact(fmt.Sprintf(messageFmt, args...)))
+}
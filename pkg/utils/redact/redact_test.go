@@ -0,0 +1,49 @@
+package redact
+
+import (
+	"testing"
+
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRedact(t *testing.T) {
+	var r *Redactor
+	if r.Redact("password=abc") != "password=abc" {
+		t.Errorf("expect nil redactor returns the origin")
+	}
+	if New("", "") != nil {
+		t.Errorf("expect nil redactor for empty values")
+	}
+	r = New("abc", "abcdef")
+	if actual := r.Redact("login abcdef failed, token abc"); actual != "login "+Mask+" failed, token "+Mask {
+		t.Errorf("unexpected redacted %s", actual)
+	}
+}
+
+func TestEventRecorder(t *testing.T) {
+	fake := record.NewFakeRecorder(1)
+	recorder := NewEventRecorder(fake, New("s3cr3t"))
+	recorder.Eventf(nil, "Normal", "Failed", "run error: %v", "auth s3cr3t denied")
+	if event := <-fake.Events; event != "Normal Failed run error: auth "+Mask+" denied" {
+		t.Errorf("unexpected event %s", event)
+	}
+	if NewEventRecorder(fake, nil) != fake {
+		t.Errorf("expect origin recorder without redactor")
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	r := New("s3cr3t")
+	m := map[string]string{"dsn": "root:s3cr3t@db", "host": "db"}
+	r.RedactMap(m)
+	if m["dsn"] != "root:"+Mask+"@db" || m["host"] != "db" {
+		t.Errorf("unexpected redacted map %v", m)
+	}
+	actual := r.RedactJSON([]byte(`{"db":{"dsn":"root:s3cr3t@db","port":3306},"tokens":["s3cr3t"]}`))
+	if expect := `{"db":{"dsn":"root:` + Mask + `@db","port":3306},"tokens":["` + Mask + `"]}`; string(actual) != expect {
+		t.Errorf("expect %s, got %s", expect, actual)
+	}
+	if actual = r.RedactJSON([]byte(`not json s3cr3t`)); string(actual) != "not json "+Mask {
+		t.Errorf("unexpected redacted %s", actual)
+	}
+}